@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pressly/goose/v3"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/buildinfo"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/config"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/database"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/migrate"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/seed"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/migrations"
+	"gopkg.in/yaml.v3"
+)
+
+const usage = `Usage: go run ./cmd/admin [flags] <command> [command flags]
+
+Flags are the same as for the server, see -help.
+
+Commands:
+  user create -email E -name N [-password P]   Create a user, a password is generated if omitted
+  user disable -email E                        Disable a user and revoke their sessions
+  user enable -email E                         Re-enable a disabled user
+  user reset-password -email E [-password P]   Set a new password and revoke all sessions
+  rotate-secret [-revoke-sessions]             Generate a new JWT secret, optionally revoking all refresh tokens
+  config                                       Print the effective configuration, secrets redacted
+  seed [-force] PATH...                        Load YAML/JSON fixture files or directories, existing records are skipped,
+                                               refused in production unless -force is given
+  check                                        Verify database connectivity and schema version
+  version                                      Print the build version, commit and time`
+
+// checkTimeout bounds the connectivity check
+const checkTimeout = 10 * time.Second
+
+func main() {
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	if len(cfg.Args()) == 0 {
+		log.Fatal(usage)
+	}
+
+	if err := run(context.Background(), cfg, cfg.Args()); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+}
+
+// run executes a single admin command
+func run(ctx context.Context, cfg *config.Config, args []string) error {
+	command, rest := args[0], args[1:]
+
+	switch command {
+	case "user":
+		return runUser(ctx, cfg, rest)
+	case "rotate-secret":
+		return runRotateSecret(ctx, cfg, rest)
+	case "config":
+		return printConfig(cfg)
+	case "seed":
+		return runSeed(ctx, cfg, rest)
+	case "check":
+		return runCheck(ctx, cfg)
+	case "version":
+		info := buildinfo.Get()
+		fmt.Printf("%s (commit %s, built %s, %s)\n", info.Version, info.Commit, info.BuildTime, info.GoVersion)
+		return nil
+	default:
+		return fmt.Errorf("invalid command %q\n\n%s", command, usage)
+	}
+}
+
+// printConfig writes the effective configuration as YAML
+func printConfig(cfg *config.Config) error {
+	out, err := yaml.Marshal(cfg.Redacted())
+	if err != nil {
+		return fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	_, err = os.Stdout.Write(out)
+	return err
+}
+
+// runSeed loads all fixture files and applies them in one transaction
+func runSeed(ctx context.Context, cfg *config.Config, args []string) error {
+	fs := newFlagSet("seed")
+	force := fs.Bool("force", false, "seed even when env is production")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := seed.CheckEnvironment(cfg.IsProduction(), *force); err != nil {
+		return err
+	}
+	paths := fs.Args()
+	if len(paths) == 0 {
+		return errors.New("seed requires at least one fixture file or directory")
+	}
+	fixtures, err := seed.LoadFiles(paths...)
+	if err != nil {
+		return err
+	}
+
+	db, err := openDB(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	fmt.Printf("🌱 Seeding from %s\n", strings.Join(paths, ", "))
+	results, err := seed.New(db, cfg.BcryptCost).Apply(ctx, fixtures)
+	if err != nil {
+		return err
+	}
+	for _, r := range results {
+		fmt.Printf("   %-12s %d inserted, %d skipped\n", r.Table, r.Inserted, r.Skipped)
+	}
+	fmt.Println("✅ Seeding completed successfully")
+	return nil
+}
+
+// runCheck pings the database and compares the schema with the embedded migrations
+func runCheck(ctx context.Context, cfg *config.Config) error {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	db, err := openDB(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	start := time.Now()
+	if err := db.PingContext(ctx); err != nil {
+		return fmt.Errorf("database ping failed: %w", err)
+	}
+	fmt.Printf("✅ Database reachable (%s)\n", time.Since(start).Round(time.Millisecond))
+
+	migrator, err := migrate.New(db, goose.DialectPostgres, migrations.FS)
+	if err != nil {
+		return fmt.Errorf("failed to initialize migrations: %w", err)
+	}
+	version, err := migrator.Version(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if version != migrator.Latest() {
+		return fmt.Errorf("schema version %d is not the latest %d, run migrations", version, migrator.Latest())
+	}
+	fmt.Printf("✅ Schema up to date (version %d)\n", version)
+	return nil
+}
+
+// openDB connects using the server's database settings
+func openDB(ctx context.Context, cfg *config.Config) (*sql.DB, error) {
+	db, err := database.Open(ctx, database.ConfigFromApp(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	return db, nil
+}
+
+// newFlagSet creates the flag set of a subcommand, errors are returned instead of exiting
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	return fs
+}
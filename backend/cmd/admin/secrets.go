@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/config"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/repository"
+)
+
+// runRotateSecret prints a new JWT secret and optionally revokes every refresh token.
+// The configuration is not written, the operator deploys the secret and restarts the server.
+func runRotateSecret(ctx context.Context, cfg *config.Config, args []string) error {
+	fs := newFlagSet("rotate-secret")
+	revoke := fs.Bool("revoke-sessions", false, "also revoke every refresh token, forcing all users to log in again")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	secret := generateSecret()
+
+	if *revoke {
+		db, err := openDB(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		n, err := repository.New(db).RefreshTokens.RevokeAll(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Revoked %d refresh tokens\n", n)
+	}
+
+	fmt.Printf("🔑 New JWT secret: %s\n", secret)
+	fmt.Println("   Set it as JWT_SECRET (or jwt_secret in the config file) and restart the server.")
+	fmt.Println("   Access tokens signed with the old secret are rejected after the restart.")
+	return nil
+}
+
+// generateSecret returns a random secret long enough for production, see config.Validate
+func generateSecret() string {
+	return rand.Text() + rand.Text()
+}
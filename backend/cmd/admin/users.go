@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/auth"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/config"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/models"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/repository"
+)
+
+// runUser executes a user subcommand
+func runUser(ctx context.Context, cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("user requires a subcommand\n\n%s", usage)
+	}
+	command, args := args[0], args[1:]
+
+	fs := newFlagSet("user " + command)
+	email := fs.String("email", "", "email of the user")
+	var name, password *string
+	switch command {
+	case "create":
+		name = fs.String("name", "", "display name of the user")
+		fallthrough
+	case "reset-password":
+		password = fs.String("password", "", "new password, generated when empty")
+	case "disable", "enable":
+	default:
+		return fmt.Errorf("invalid user command %q\n\n%s", command, usage)
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *email == "" {
+		return errors.New("-email is required")
+	}
+
+	generated := false
+	if password != nil && *password == "" {
+		*password = generatePassword()
+		generated = true
+	}
+
+	db, err := openDB(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tokens, err := auth.NewTokenService(cfg.JWTSecret, cfg.AccessTokenTTL)
+	if err != nil {
+		return err
+	}
+	repos := repository.New(db)
+	svc, err := auth.NewService(repos.Users, repos.RefreshTokens, tokens, cfg.RefreshTokenTTL, cfg.BcryptCost)
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case "create":
+		user, err := svc.CreateUser(ctx, models.RegisterRequest{Email: *email, Name: *name, Password: *password})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Created user %d <%s>\n", user.ID, user.Email)
+	case "reset-password":
+		if err := svc.ResetPassword(ctx, *email, *password); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Password reset for %s, all sessions revoked\n", *email)
+	case "disable":
+		if err := svc.SetDisabled(ctx, *email, true); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Disabled %s, all sessions revoked\n", *email)
+	case "enable":
+		if err := svc.SetDisabled(ctx, *email, false); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Enabled %s\n", *email)
+	}
+
+	if generated {
+		fmt.Printf("🔑 Generated password: %s\n", *password)
+	}
+	return nil
+}
+
+// generatePassword returns a random password accepted by models.ValidatePassword
+func generatePassword() string {
+	for {
+		password := rand.Text()
+		if models.ValidatePassword(password) == nil {
+			return password
+		}
+	}
+}
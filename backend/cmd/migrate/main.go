@@ -1,41 +1,193 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/pressly/goose/v3"
 	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/config"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/database"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/migrate"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/seed"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/migrations"
 )
 
+const usage = `Usage: go run cmd/migrate/main.go [flags] <command> [version]
+
+Commands:
+  up            Apply all pending migrations
+  up-to N       Apply pending migrations up to and including version N
+  down          Roll back the most recently applied migration
+  down-to N     Roll back migrations down to, but not including, version N (0 rolls back all)
+  redo          Roll back the most recent migration and apply it again
+  status        Show applied and pending migrations
+  version       Print the current schema version
+  seed [-force] [PATH]
+                Load YAML/JSON fixtures from files or directories (default fixtures), existing records are skipped.
+                Refused in production unless -force is given`
+
+// defaultFixtures is the fixture directory loaded by seed without arguments
+const defaultFixtures = "fixtures"
+
 func main() {
-	if len(os.Args) < 2 {
-		log.Fatal("Usage: go run cmd/migrate/main.go [up|down]")
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	if len(cfg.Args()) == 0 {
+		log.Fatal(usage)
+	}
+
+	db, err := database.Open(context.Background(), database.ConfigFromApp(cfg))
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	migrator, err := migrate.New(db, goose.DialectPostgres, migrations.FS)
+	if err != nil {
+		log.Fatalf("Failed to initialize migrations: %v", err)
+	}
+
+	seeder := seed.New(db, cfg.BcryptCost)
+	if err := run(context.Background(), migrator, seeder, cfg.IsProduction(), cfg.Args()); err != nil {
+		db.Close()
+		log.Fatalf("❌ %v", err)
 	}
+}
 
-	cfg := config.Load()
-	direction := os.Args[1]
+// run executes a single migrate command, production guards seed
+func run(ctx context.Context, m *migrate.Migrator, seeder *seed.Seeder, production bool, args []string) error {
+	command := args[0]
 
-	switch direction {
+	switch command {
 	case "up":
-		runMigrationsUp(cfg)
+		fmt.Println("🔄 Running migrations UP")
+		results, err := m.Up(ctx)
+		printResults(results)
+		if err != nil {
+			return err
+		}
+		fmt.Println("✅ Migrations completed successfully")
+	case "up-to":
+		version, err := parseVersion(args)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("🔄 Running migrations UP to %d\n", version)
+		results, err := m.UpTo(ctx, version)
+		printResults(results)
+		if err != nil {
+			return err
+		}
+		fmt.Println("✅ Migrations completed successfully")
 	case "down":
-		runMigrationsDown(cfg)
+		fmt.Println("🔄 Rolling back the latest migration")
+		result, err := m.Down(ctx)
+		if err != nil {
+			return err
+		}
+		printResults([]migrate.Result{result})
+		fmt.Println("✅ Migrations rollback completed successfully")
+	case "down-to":
+		version, err := parseVersion(args)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("🔄 Rolling back migrations DOWN to %d\n", version)
+		results, err := m.DownTo(ctx, version)
+		printResults(results)
+		if err != nil {
+			return err
+		}
+		fmt.Println("✅ Migrations rollback completed successfully")
+	case "redo":
+		fmt.Println("🔄 Re-applying the latest migration")
+		results, err := m.Redo(ctx)
+		printResults(results)
+		if err != nil {
+			return err
+		}
+		fmt.Println("✅ Migration re-applied successfully")
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		printStatus(statuses)
+	case "version":
+		version, err := m.Version(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Current version: %d (latest available: %d)\n", version, m.Latest())
+	case "seed":
+		fs := flag.NewFlagSet("seed", flag.ContinueOnError)
+		force := fs.Bool("force", false, "seed even when env is production")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if err := seed.CheckEnvironment(production, *force); err != nil {
+			return err
+		}
+		paths := fs.Args()
+		if len(paths) == 0 {
+			paths = []string{defaultFixtures}
+		}
+		fixtures, err := seed.LoadFiles(paths...)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("🌱 Seeding from %s\n", strings.Join(paths, ", "))
+		results, err := seeder.Apply(ctx, fixtures)
+		if err != nil {
+			return err
+		}
+		printSeedResults(results)
+		fmt.Println("✅ Seeding completed successfully")
 	default:
-		log.Fatal("Invalid direction. Use 'up' or 'down'")
+		return fmt.Errorf("invalid command %q\n\n%s", command, usage)
+	}
+	return nil
+}
+
+// parseVersion reads the version argument of up-to and down-to
+func parseVersion(args []string) (int64, error) {
+	if len(args) < 2 {
+		return 0, fmt.Errorf("%s requires a version argument", args[0])
+	}
+	version, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil || version < 0 {
+		return 0, fmt.Errorf("invalid version %q", args[1])
 	}
+	return version, nil
 }
 
-func runMigrationsUp(cfg *config.Config) {
-	fmt.Printf("🔄 Running migrations UP against %s\n", cfg.DatabaseURL)
-	// TODO: Implement actual migration logic
-	// This would typically use a migration library like golang-migrate
-	fmt.Println("✅ Migrations completed successfully")
+func printResults(results []migrate.Result) {
+	for _, r := range results {
+		fmt.Printf("   %-4s %s (%s)\n", r.Direction, r.Name, r.Duration.Round(time.Millisecond))
+	}
 }
 
-func runMigrationsDown(cfg *config.Config) {
-	fmt.Printf("🔄 Running migrations DOWN against %s\n", cfg.DatabaseURL)
-	// TODO: Implement actual migration logic
-	// This would typically use a migration library like golang-migrate
-	fmt.Println("✅ Migrations rollback completed successfully")
+func printSeedResults(results []seed.Result) {
+	for _, r := range results {
+		fmt.Printf("   %-12s %d inserted, %d skipped\n", r.Table, r.Inserted, r.Skipped)
+	}
+}
+
+func printStatus(statuses []migrate.Status) {
+	fmt.Printf("%-24s %-10s %s\n", "Applied At", "State", "Migration")
+	for _, s := range statuses {
+		appliedAt := "-"
+		if s.State == migrate.StateApplied {
+			appliedAt = s.AppliedAt.Local().Format(time.DateTime)
+		}
+		fmt.Printf("%-24s %-10s %s\n", appliedAt, s.State, s.Name)
+	}
 }
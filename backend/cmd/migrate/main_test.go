@@ -0,0 +1,22 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/seed"
+)
+
+func TestSeedRefusedInProduction(t *testing.T) {
+	// The guard runs before the fixtures are read or the database is touched
+	err := run(context.Background(), nil, nil, true, []string{"seed"})
+	if !errors.Is(err, seed.ErrProduction) {
+		t.Errorf("Expected ErrProduction, got %v", err)
+	}
+
+	err = run(context.Background(), nil, nil, true, []string{"seed", "-force", "does-not-exist"})
+	if err == nil || errors.Is(err, seed.ErrProduction) {
+		t.Errorf("Expected -force to pass the guard and fail on the missing fixtures, got %v", err)
+	}
+}
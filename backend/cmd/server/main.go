@@ -2,72 +2,314 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/pressly/goose/v3"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/apierror"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/auth"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/buildinfo"
 	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/config"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/database"
 	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/handlers"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/health"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/lifecycle"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/logging"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/metrics"
 	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/middleware"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/migrate"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/openapi"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/ratelimit"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/repository"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/tlsutil"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/tracing"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/migrations"
 )
 
 func main() {
 	// Load configuration
-	cfg := config.Load()
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	// Initialize structured logger
+	logger, err := logging.New(os.Stdout, cfg.LogLevel)
+	if err != nil {
+		log.Fatalf("Invalid log configuration: %v", err)
+	}
+	slog.SetDefault(logger)
+
+	// Tracing, spans are only exported when an exporter is configured
+	tracerProvider, err := tracing.Setup(context.Background(), tracing.Config{
+		ServiceName:    "course-backend",
+		ServiceVersion: buildinfo.Get().Version,
+		Exporter:       cfg.TraceExporter,
+		Endpoint:       cfg.TraceEndpoint,
+		SampleRatio:    cfg.TraceSampleRatio,
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize tracing: %v", err)
+	}
+
+	// Cleanup hooks run in reverse order: HTTP server, database, tracing
+	lifecycleManager := lifecycle.New(logger)
+	lifecycleManager.Register("tracing", tracerProvider.Shutdown)
+
+	// Connect to the database
+	db, err := database.Open(context.Background(), database.ConfigFromApp(cfg))
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	lifecycleManager.Register("database", func(ctx context.Context) error {
+		return database.Close(db)
+	})
+
+	migrator, err := migrate.New(db, goose.DialectPostgres, migrations.FS)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	// Repositories share the pool, handlers reach the database only through them
+	repos := repository.New(db)
+
+	// Authentication
+	tokens, err := auth.NewTokenService(cfg.JWTSecret, cfg.AccessTokenTTL)
+	if err != nil {
+		log.Fatalf("Failed to initialize token service: %v", err)
+	}
+	authService, err := auth.NewService(repos.Users, repos.RefreshTokens, tokens, cfg.RefreshTokenTTL, cfg.BcryptCost)
+	if err != nil {
+		log.Fatalf("Failed to initialize auth service: %v", err)
+	}
+	authHandler := handlers.NewAuthHandler(authService)
 
 	// Initialize Gin router
-	if cfg.Env == "production" {
+	if cfg.IsProduction() {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		apierror.UseJSONFieldNames(v)
+	}
+
 	router := gin.New()
+	// Without trusted proxies ClientIP is the peer address, a forged X-Forwarded-For cannot dodge rate limits
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Fatalf("Invalid trusted proxies: %v", err)
+	}
+	router.HandleMethodNotAllowed = true
+	router.NoRoute(handlers.NotFound)
+	router.NoMethod(handlers.MethodNotAllowed)
 
 	// Add middleware
-	router.Use(gin.Logger())
-	router.Use(gin.Recovery())
-	router.Use(middleware.CORS())
+	router.Use(middleware.Tracing(tracing.Tracer()))
+	router.Use(middleware.RequestLogger(logger))
+	// Metrics are collected here but served on their own port, away from the public API
+	var metricsServer *http.Server
+	if cfg.MetricsEnabled {
+		appMetrics := metrics.New()
+		if err := appMetrics.RegisterDB(db, "postgres"); err != nil {
+			log.Fatalf("Failed to register database metrics: %v", err)
+		}
+		router.Use(middleware.Metrics(appMetrics))
 
-	// Health check endpoint
-	router.GET("/health", handlers.HealthCheck)
+		mux := http.NewServeMux()
+		mux.Handle("GET /metrics", appMetrics.Handler())
+		metricsServer = &http.Server{
+			Addr:              ":" + cfg.MetricsPort,
+			Handler:           mux,
+			ReadHeaderTimeout: cfg.HTTPReadHeaderTimeout,
+			IdleTimeout:       cfg.HTTPIdleTimeout,
+		}
+	}
+	router.Use(middleware.Recovery(logger))
+	router.Use(middleware.ErrorHandler())
+	corsOptions := middleware.DefaultCORSOptions(cfg.CORSOrigins)
+	corsOptions.MaxAge = cfg.CORSMaxAge
+	router.Use(middleware.CORS(corsOptions))
+	if cfg.CompressionEnabled {
+		compressOptions := middleware.DefaultCompressOptions()
+		compressOptions.MinSize = cfg.CompressionMinSize
+		router.Use(middleware.Compress(compressOptions))
+	}
+	router.Use(middleware.ETag())
 
-	// API routes
-	api := router.Group("/api/v1")
-	{
-		api.GET("/ping", handlers.Ping)
-		// Add more routes as needed
+	// Readiness checks, dependencies register their own checks as they come up
+	checks := health.NewRegistry()
+	checks.Register("database", cfg.HealthCheckTimeout, health.DatabaseCheck(db))
+	checks.Register("migrations", cfg.HealthCheckTimeout, health.MigrationCheck(migrator, migrator.Latest()))
+	checks.Register("disk", cfg.HealthCheckTimeout, health.DiskSpaceCheck(cfg.HealthDiskPath, uint64(cfg.HealthMinFreeDisk)<<20))
+	checks.Register("shutdown", cfg.HealthCheckTimeout, health.CheckerFunc(lifecycleManager.ReadinessCheck))
+
+	// Rate limits, validated by config.Load
+	var apiLimit, authLimit, userLimit []gin.HandlerFunc
+	if cfg.RateLimitEnabled {
+		store := ratelimit.NewMemoryStore()
+		apiLimit = []gin.HandlerFunc{middleware.RateLimit(store, "api", mustParseLimit(cfg.RateLimitAPI), middleware.KeyByIP)}
+		authLimit = []gin.HandlerFunc{middleware.RateLimit(store, "auth", mustParseLimit(cfg.RateLimitAuth), middleware.KeyByIP)}
+		userLimit = []gin.HandlerFunc{middleware.RateLimit(store, "user", mustParseLimit(cfg.RateLimitUser), middleware.KeyByUser)}
 	}
 
+	registerRoutes(router, routeDeps{
+		checks:      checks,
+		auth:        authHandler,
+		requireAuth: middleware.RequireAuth(tokens, repos.Users),
+		apiLimit:    apiLimit,
+		authLimit:   authLimit,
+		userLimit:   userLimit,
+	})
+
+	// API documentation generated from the registered routes
+	doc, err := openapi.Build(router.Routes(), apiDocs, openapiOptions())
+	if err != nil {
+		log.Fatalf("Failed to build OpenAPI document: %v", err)
+	}
+	docHandler, err := openapi.Handler(doc)
+	if err != nil {
+		log.Fatalf("Failed to encode OpenAPI document: %v", err)
+	}
+	router.GET("/openapi.json", docHandler)
+	router.GET("/docs", openapi.ViewerHandler(doc.Info.Title, "/openapi.json"))
+
 	// Create HTTP server
 	server := &http.Server{
-		Addr:    ":" + cfg.Port,
-		Handler: router,
+		Addr:              ":" + cfg.Port,
+		Handler:           router,
+		ReadTimeout:       cfg.HTTPReadTimeout,
+		ReadHeaderTimeout: cfg.HTTPReadHeaderTimeout,
+		WriteTimeout:      cfg.HTTPWriteTimeout,
+		IdleTimeout:       cfg.HTTPIdleTimeout,
+	}
+
+	// Optional TLS, certificates are reloaded when their files change
+	if cfg.TLSEnabled {
+		reloader, err := tlsutil.NewCertReloader(cfg.TLSCertFile, cfg.TLSKeyFile, logger)
+		if err != nil {
+			log.Fatalf("Failed to load TLS certificate: %v", err)
+		}
+		server.TLSConfig, err = tlsutil.ServerConfig(cfg.TLSMinVersion, cfg.TLSCipherPolicy, reloader.GetCertificate)
+		if err != nil {
+			log.Fatalf("Invalid TLS configuration: %v", err)
+		}
+
+		watchCtx, stopWatch := context.WithCancel(context.Background())
+		go func() {
+			if err := reloader.Watch(watchCtx); err != nil {
+				logger.Error("certificate reloading disabled", slog.String("error", err.Error()))
+			}
+		}()
+		lifecycleManager.Register("tls-reloader", func(ctx context.Context) error {
+			stopWatch()
+			return nil
+		})
 	}
+	lifecycleManager.Register("http", server.Shutdown)
 
-	// Start server in a goroutine
+	// Start servers in goroutines
+	serverErr := make(chan error, 3)
 	go func() {
-		log.Printf("🚀 Server starting on port %s", cfg.Port)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Failed to start server: %v", err)
+		var err error
+		if cfg.TLSEnabled {
+			log.Printf("🔒 HTTPS server starting on port %s", cfg.Port)
+			err = server.ListenAndServeTLS("", "")
+		} else {
+			log.Printf("🚀 Server starting on port %s", cfg.Port)
+			err = server.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
 		}
 	}()
 
-	// Wait for interrupt signal to gracefully shutdown the server
+	if cfg.TLSEnabled && cfg.TLSRedirectPort != "" {
+		redirect := &http.Server{
+			Addr:              ":" + cfg.TLSRedirectPort,
+			Handler:           tlsutil.RedirectHandler(cfg.Port),
+			ReadTimeout:       cfg.HTTPReadTimeout,
+			ReadHeaderTimeout: cfg.HTTPReadHeaderTimeout,
+			WriteTimeout:      cfg.HTTPWriteTimeout,
+			IdleTimeout:       cfg.HTTPIdleTimeout,
+		}
+		lifecycleManager.Register("http-redirect", redirect.Shutdown)
+		go func() {
+			log.Printf("↪️  Redirecting HTTP on port %s to HTTPS", cfg.TLSRedirectPort)
+			if err := redirect.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				serverErr <- err
+			}
+		}()
+	}
+
+	if metricsServer != nil {
+		lifecycleManager.Register("metrics", metricsServer.Shutdown)
+		go func() {
+			log.Printf("📈 Metrics listening on port %s", cfg.MetricsPort)
+			if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				serverErr <- err
+			}
+		}()
+	}
+
+	// Wait for an interrupt signal or a failing listener
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-	log.Println("🛑 Shutting down server...")
 
-	// Give outstanding requests 10 seconds to complete
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	code := exitOK
+	select {
+	case sig := <-quit:
+		log.Printf("🛑 Received %s, shutting down server...", sig)
+		// A second signal skips the drain delay
+		drainCtx, stopDrain := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		server.SetKeepAlivesEnabled(false)
+		lifecycleManager.Drain(drainCtx, cfg.ShutdownDrainDelay)
+		stopDrain()
+	case err := <-serverErr:
+		log.Printf("❌ Server failed: %v", err)
+		code = exitServerError
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
 	defer cancel()
 
-	if err := server.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+	if err := lifecycleManager.Shutdown(ctx); err != nil {
+		log.Printf("❌ Shutdown incomplete: %v", err)
+		cancel()
+		os.Exit(shutdownExitCode(err))
 	}
 
 	log.Println("✅ Server exited")
+	cancel()
+	os.Exit(code)
+}
+
+// Process exit codes
+const (
+	exitOK              = 0
+	exitServerError     = 1
+	exitShutdownError   = 2
+	exitShutdownTimeout = 3
+)
+
+// shutdownExitCode tells a timed out shutdown apart from failing hooks
+func shutdownExitCode(err error) int {
+	if errors.Is(err, lifecycle.ErrShutdownTimeout) {
+		return exitShutdownTimeout
+	}
+	return exitShutdownError
+}
+
+func mustParseLimit(spec string) ratelimit.Limit {
+	limit, err := ratelimit.ParseLimit(spec)
+	if err != nil {
+		log.Fatalf("Invalid rate limit: %v", err)
+	}
+	return limit
 }
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/apierror"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/buildinfo"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/handlers"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/health"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/models"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/openapi"
+)
+
+// routeDeps holds what the routes need, built by main
+type routeDeps struct {
+	checks      *health.Registry
+	auth        *handlers.AuthHandler
+	requireAuth gin.HandlerFunc
+	apiLimit    []gin.HandlerFunc
+	authLimit   []gin.HandlerFunc
+	userLimit   []gin.HandlerFunc
+}
+
+// registerRoutes adds the health and API routes. Every route registered here
+// must be documented in apiDocs, TestRoutesDocumented enforces it.
+func registerRoutes(router *gin.Engine, d routeDeps) {
+	// Health check endpoints
+	router.GET("/health", handlers.HealthCheck)
+	router.GET("/livez", handlers.Liveness)
+	router.GET("/readyz", handlers.Readiness(d.checks))
+
+	// API routes
+	api := router.Group("/api/v1", d.apiLimit...)
+	{
+		api.GET("/ping", handlers.Ping)
+
+		authRoutes := api.Group("/auth", d.authLimit...)
+		authRoutes.POST("/register", d.auth.Register)
+		authRoutes.POST("/login", d.auth.Login)
+		authRoutes.POST("/refresh", d.auth.Refresh)
+		authRoutes.POST("/logout", d.auth.Logout)
+
+		protected := api.Group("", d.requireAuth)
+		protected.Use(d.userLimit...)
+		protected.GET("/me", d.auth.Me)
+		// Add more routes as needed
+	}
+}
+
+// apiDocs documents the routes of registerRoutes
+var apiDocs = openapi.Routes{
+	"GET /health": {
+		Summary:   "Legacy health check",
+		Tags:      []string{"health"},
+		Responses: map[int]any{http.StatusOK: handlers.HealthResponse{}},
+	},
+	"GET /livez": {
+		Summary:   "Liveness probe",
+		Tags:      []string{"health"},
+		Responses: map[int]any{http.StatusOK: handlers.LivenessResponse{}},
+	},
+	"GET /readyz": {
+		Summary:     "Readiness probe",
+		Description: "Runs every readiness check, returns 503 when any of them fails.",
+		Tags:        []string{"health"},
+		Responses: map[int]any{
+			http.StatusOK:                 handlers.ReadinessResponse{},
+			http.StatusServiceUnavailable: handlers.ReadinessResponse{},
+		},
+	},
+	"GET /api/v1/ping": {
+		Summary:   "Ping",
+		Tags:      []string{"api"},
+		Responses: map[int]any{http.StatusOK: handlers.PingResponse{}},
+	},
+	"POST /api/v1/auth/register": {
+		Summary:   "Create an account",
+		Tags:      []string{"auth"},
+		Request:   models.RegisterRequest{},
+		Responses: map[int]any{http.StatusCreated: models.AuthResponse{}},
+	},
+	"POST /api/v1/auth/login": {
+		Summary:   "Log in with email and password",
+		Tags:      []string{"auth"},
+		Request:   models.LoginRequest{},
+		Responses: map[int]any{http.StatusOK: models.AuthResponse{}},
+	},
+	"POST /api/v1/auth/refresh": {
+		Summary:     "Rotate a refresh token",
+		Description: "The presented refresh token is revoked and a new token pair is issued.",
+		Tags:        []string{"auth"},
+		Request:     models.RefreshRequest{},
+		Responses:   map[int]any{http.StatusOK: models.TokenPair{}},
+	},
+	"POST /api/v1/auth/logout": {
+		Summary:   "Revoke a refresh token",
+		Tags:      []string{"auth"},
+		Request:   models.RefreshRequest{},
+		Responses: map[int]any{http.StatusNoContent: nil},
+	},
+	"GET /api/v1/me": {
+		Summary:   "Current user",
+		Tags:      []string{"auth"},
+		Auth:      true,
+		Responses: map[int]any{http.StatusOK: models.User{}},
+	},
+}
+
+// openapiOptions configures the generated document
+func openapiOptions() openapi.Options {
+	return openapi.Options{
+		Info: openapi.Info{
+			Title:   "Course API",
+			Version: buildinfo.Get().Version,
+		},
+		Ignore: []string{"GET /openapi.json", "GET /docs"},
+		Error:  apierror.Envelope{},
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/handlers"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/health"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/openapi"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// testRouter registers the real routes with dependencies that are never called
+func testRouter() *gin.Engine {
+	router := gin.New()
+	registerRoutes(router, routeDeps{
+		checks:      health.NewRegistry(),
+		auth:        handlers.NewAuthHandler(nil),
+		requireAuth: func(c *gin.Context) {},
+	})
+	return router
+}
+
+// TestRoutesDocumented fails when a route is added to registerRoutes without
+// an apiDocs entry, or an entry is left behind for a removed route
+func TestRoutesDocumented(t *testing.T) {
+	router := testRouter()
+
+	doc, err := openapi.Build(router.Routes(), apiDocs, openapiOptions())
+	if err != nil {
+		t.Fatalf("OpenAPI document is out of date:\n%v", err)
+	}
+
+	for path, item := range doc.Paths {
+		for method, op := range *item {
+			for status, resp := range op.Responses {
+				if status == "default" || status == "204" {
+					continue
+				}
+				if resp.Content["application/json"].Schema == nil {
+					t.Errorf("%s %s: response %s has no schema", method, path, status)
+				}
+			}
+			if (method == "post" || method == "put" || method == "patch") && op.RequestBody == nil {
+				t.Errorf("%s %s: request body is not documented", method, path)
+			}
+		}
+	}
+}
+
+func TestUndocumentedRouteFails(t *testing.T) {
+	router := testRouter()
+	router.GET("/api/v1/undocumented", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	if _, err := openapi.Build(router.Routes(), apiDocs, openapiOptions()); err == nil {
+		t.Error("Expected an undocumented route to fail the build")
+	}
+}
@@ -0,0 +1,157 @@
+// Package apierror defines the error envelope returned by every API endpoint.
+// It knows nothing about domain packages, the middleware maps their errors.
+package apierror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Error codes, stable identifiers clients can switch on
+const (
+	CodeInvalidBody      = "invalid_body"
+	CodeValidation       = "validation_failed"
+	CodeUnauthorized     = "unauthorized"
+	CodeForbidden        = "forbidden"
+	CodeNotFound         = "not_found"
+	CodeMethodNotAllowed = "method_not_allowed"
+	CodeConflict         = "conflict"
+	CodeRateLimited      = "rate_limited"
+	CodeInternal         = "internal_error"
+)
+
+// Error is an API error. Status is the HTTP status it is sent with, Err the
+// underlying cause which is logged but never sent to the client.
+type Error struct {
+	Status    int    `json:"-"`
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Details   any    `json:"details,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+	Err       error  `json:"-"`
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Message, e.Err)
+	}
+	return e.Code + ": " + e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Envelope is the JSON body of every error response
+type Envelope struct {
+	Error *Error `json:"error"`
+}
+
+// FieldError describes one invalid field of a request body
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// New creates an API error with the given status and code
+func New(status int, code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+// Validation creates a 400 error, details usually list the invalid fields
+func Validation(message string, details any) *Error {
+	return &Error{Status: http.StatusBadRequest, Code: CodeValidation, Message: message, Details: details}
+}
+
+// Unauthorized creates a 401 error
+func Unauthorized(message string) *Error {
+	return New(http.StatusUnauthorized, CodeUnauthorized, message)
+}
+
+// NotFound creates a 404 error
+func NotFound(message string) *Error {
+	return New(http.StatusNotFound, CodeNotFound, message)
+}
+
+// Conflict creates a 409 error
+func Conflict(message string) *Error {
+	return New(http.StatusConflict, CodeConflict, message)
+}
+
+// Internal creates a 500 error hiding err from the client
+func Internal(err error) *Error {
+	return &Error{Status: http.StatusInternalServerError, Code: CodeInternal, Message: "internal server error", Err: err}
+}
+
+// From converts err to an API error. Errors that are already API errors are
+// returned as is, anything else becomes an internal error. Callers map their
+// domain errors to API errors before falling back to From.
+func From(err error) *Error {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	return Internal(err)
+}
+
+// Binding converts an error returned while binding a request body. Failed
+// binding tags are listed field by field in the details, anything else is
+// reported as an unreadable body.
+func Binding(err error) *Error {
+	var fieldErrs validator.ValidationErrors
+	if errors.As(err, &fieldErrs) {
+		details := make([]FieldError, 0, len(fieldErrs))
+		for _, fe := range fieldErrs {
+			details = append(details, FieldError{Field: fieldName(fe), Message: fieldMessage(fe)})
+		}
+		return &Error{Status: http.StatusBadRequest, Code: CodeValidation, Message: "request validation failed", Details: details, Err: err}
+	}
+
+	return &Error{Status: http.StatusBadRequest, Code: CodeInvalidBody, Message: "invalid JSON body", Err: err}
+}
+
+// UseJSONFieldNames makes v report fields by their JSON name, so that
+// validation details match the request body seen by clients
+func UseJSONFieldNames(v *validator.Validate) {
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+}
+
+// fieldName returns the field path without the top-level struct name
+func fieldName(fe validator.FieldError) string {
+	ns := fe.Namespace()
+	if _, rest, ok := strings.Cut(ns, "."); ok {
+		return rest
+	}
+	return ns
+}
+
+func fieldMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "min":
+		return "must be at least " + fe.Param()
+	case "max":
+		return "must be at most " + fe.Param()
+	case "oneof":
+		return "must be one of " + fe.Param()
+	default:
+		return "failed the " + fe.Tag() + " check"
+	}
+}
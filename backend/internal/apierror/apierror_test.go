@@ -0,0 +1,73 @@
+package apierror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+)
+
+func TestFrom(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"api error", Conflict("taken"), http.StatusConflict, CodeConflict},
+		{"wrapped api error", fmt.Errorf("handler: %w", NotFound("no post")), http.StatusNotFound, CodeNotFound},
+		{"unknown", errors.New("connection refused"), http.StatusInternalServerError, CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := From(tt.err)
+			if got.Status != tt.status || got.Code != tt.code {
+				t.Errorf("Expected %d %s, got %d %s", tt.status, tt.code, got.Status, got.Code)
+			}
+		})
+	}
+}
+
+func TestFromHidesInternalCause(t *testing.T) {
+	cause := errors.New("pq: password authentication failed")
+	got := From(cause)
+	if got.Message != "internal server error" {
+		t.Errorf("Internal cause leaked into the message: %q", got.Message)
+	}
+	if !errors.Is(got, cause) {
+		t.Error("Expected the cause to be kept for logging")
+	}
+}
+
+func TestBinding(t *testing.T) {
+	type request struct {
+		Email string `json:"email" validate:"required,email"`
+		Name  string `json:"name" validate:"required"`
+	}
+
+	v := validator.New()
+	UseJSONFieldNames(v)
+	err := v.Struct(request{Email: "not-an-email"})
+
+	got := Binding(err)
+	if got.Status != http.StatusBadRequest || got.Code != CodeValidation {
+		t.Fatalf("Expected validation error, got %d %s", got.Status, got.Code)
+	}
+	details, ok := got.Details.([]FieldError)
+	if !ok || len(details) != 2 {
+		t.Fatalf("Expected 2 field errors, got %#v", got.Details)
+	}
+	if details[0] != (FieldError{Field: "email", Message: "must be a valid email address"}) {
+		t.Errorf("Unexpected field error %+v", details[0])
+	}
+	if details[1] != (FieldError{Field: "name", Message: "is required"}) {
+		t.Errorf("Unexpected field error %+v", details[1])
+	}
+
+	if got := Binding(errors.New("unexpected EOF")); got.Code != CodeInvalidBody {
+		t.Errorf("Expected %s for unreadable body, got %s", CodeInvalidBody, got.Code)
+	}
+}
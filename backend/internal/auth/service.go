@@ -0,0 +1,250 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/models"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/repository"
+)
+
+// Authentication errors
+var (
+	ErrInvalidCredentials  = errors.New("invalid email or password")
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrUserDisabled        = errors.New("user account is disabled")
+)
+
+// UserStore is the user persistence used by the service
+type UserStore interface {
+	Create(ctx context.Context, user *models.User) error
+	GetByID(ctx context.Context, id int64) (*models.User, error)
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	UpdatePassword(ctx context.Context, id int64, passwordHash string) error
+	SetDisabled(ctx context.Context, id int64, disabled bool) error
+}
+
+// RefreshTokenStore is the refresh token persistence used by the service
+type RefreshTokenStore interface {
+	Create(ctx context.Context, token *models.RefreshToken) error
+	GetByHash(ctx context.Context, hash string) (*models.RefreshToken, error)
+	Revoke(ctx context.Context, id int64) (bool, error)
+	RevokeAllForUser(ctx context.Context, userID int64) error
+}
+
+// Service implements registration, login and the refresh token lifecycle
+type Service struct {
+	users      UserStore
+	refresh    RefreshTokenStore
+	tokens     *TokenService
+	refreshTTL time.Duration
+	bcryptCost int
+	dummyHash  string
+	now        func() time.Time
+}
+
+// NewService creates an authentication service
+func NewService(users UserStore, refresh RefreshTokenStore, tokens *TokenService, refreshTTL time.Duration, bcryptCost int) (*Service, error) {
+	// Compared against on unknown emails so that login timing does not reveal registered users
+	dummyHash, err := HashPassword("dummy-password-1", bcryptCost)
+	if err != nil {
+		return nil, err
+	}
+	return &Service{
+		users:      users,
+		refresh:    refresh,
+		tokens:     tokens,
+		refreshTTL: refreshTTL,
+		bcryptCost: bcryptCost,
+		dummyHash:  dummyHash,
+		now:        time.Now,
+	}, nil
+}
+
+// Tokens returns the access token service, used by the auth middleware
+func (s *Service) Tokens() *TokenService {
+	return s.tokens
+}
+
+// Register creates a new user and logs them in
+func (s *Service) Register(ctx context.Context, req models.RegisterRequest) (*models.User, models.TokenPair, error) {
+	user, err := s.CreateUser(ctx, req)
+	if err != nil {
+		return nil, models.TokenPair{}, err
+	}
+
+	pair, err := s.issue(ctx, user)
+	if err != nil {
+		return nil, models.TokenPair{}, err
+	}
+	return user, pair, nil
+}
+
+// CreateUser validates the request and stores a new user without logging in
+func (s *Service) CreateUser(ctx context.Context, req models.RegisterRequest) (*models.User, error) {
+	req.Normalize()
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	hash, err := HashPassword(req.Password, s.bcryptCost)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{Email: req.Email, Name: req.Name, PasswordHash: hash}
+	if err := s.users.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// ResetPassword sets a new password for the user with email and revokes all
+// of their sessions
+func (s *Service) ResetPassword(ctx context.Context, email, password string) error {
+	if err := models.ValidatePassword(password); err != nil {
+		return err
+	}
+	user, err := s.users.GetByEmail(ctx, models.NormalizeEmail(email))
+	if err != nil {
+		return err
+	}
+
+	hash, err := HashPassword(password, s.bcryptCost)
+	if err != nil {
+		return err
+	}
+	if err := s.users.UpdatePassword(ctx, user.ID, hash); err != nil {
+		return err
+	}
+	return s.refresh.RevokeAllForUser(ctx, user.ID)
+}
+
+// SetDisabled disables or re-enables the user with email. Disabling revokes
+// all sessions; access tokens already issued are rejected by the middleware.
+func (s *Service) SetDisabled(ctx context.Context, email string, disabled bool) error {
+	user, err := s.users.GetByEmail(ctx, models.NormalizeEmail(email))
+	if err != nil {
+		return err
+	}
+	if err := s.users.SetDisabled(ctx, user.ID, disabled); err != nil {
+		return err
+	}
+	if !disabled {
+		return nil
+	}
+	return s.refresh.RevokeAllForUser(ctx, user.ID)
+}
+
+// Login checks the credentials and issues a new token pair
+func (s *Service) Login(ctx context.Context, req models.LoginRequest) (*models.User, models.TokenPair, error) {
+	user, err := s.users.GetByEmail(ctx, models.NormalizeEmail(req.Email))
+	if errors.Is(err, repository.ErrNotFound) {
+		VerifyPassword(req.Password, s.dummyHash)
+		return nil, models.TokenPair{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, models.TokenPair{}, err
+	}
+
+	if !VerifyPassword(req.Password, user.PasswordHash) {
+		return nil, models.TokenPair{}, ErrInvalidCredentials
+	}
+	if user.Disabled() {
+		return nil, models.TokenPair{}, ErrUserDisabled
+	}
+
+	pair, err := s.issue(ctx, user)
+	if err != nil {
+		return nil, models.TokenPair{}, err
+	}
+	return user, pair, nil
+}
+
+// Refresh rotates a refresh token: the presented token is revoked and a new
+// pair is issued. Presenting an already revoked token revokes every session
+// of the user, since it means the token was stolen or replayed.
+func (s *Service) Refresh(ctx context.Context, refreshToken string) (models.TokenPair, error) {
+	stored, err := s.refresh.GetByHash(ctx, HashRefreshToken(refreshToken))
+	if errors.Is(err, repository.ErrNotFound) {
+		return models.TokenPair{}, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+
+	if stored.RevokedAt != nil {
+		if err := s.refresh.RevokeAllForUser(ctx, stored.UserID); err != nil {
+			return models.TokenPair{}, err
+		}
+		return models.TokenPair{}, ErrInvalidRefreshToken
+	}
+	if !s.now().Before(stored.ExpiresAt) {
+		return models.TokenPair{}, ErrInvalidRefreshToken
+	}
+
+	revoked, err := s.refresh.Revoke(ctx, stored.ID)
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+	if !revoked {
+		// Lost a race against a concurrent refresh with the same token
+		return models.TokenPair{}, ErrInvalidRefreshToken
+	}
+
+	user, err := s.users.GetByID(ctx, stored.UserID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return models.TokenPair{}, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+	if user.Disabled() {
+		return models.TokenPair{}, ErrUserDisabled
+	}
+
+	return s.issue(ctx, user)
+}
+
+// Logout revokes a refresh token, unknown tokens are ignored
+func (s *Service) Logout(ctx context.Context, refreshToken string) error {
+	stored, err := s.refresh.GetByHash(ctx, HashRefreshToken(refreshToken))
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	_, err = s.refresh.Revoke(ctx, stored.ID)
+	return err
+}
+
+// issue creates an access token and stores a new refresh token for user
+func (s *Service) issue(ctx context.Context, user *models.User) (models.TokenPair, error) {
+	access, expiresAt, err := s.tokens.GenerateAccessToken(user.ID, user.Email)
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+
+	refresh, hash, err := NewRefreshToken()
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+	stored := &models.RefreshToken{
+		UserID:    user.ID,
+		TokenHash: hash,
+		ExpiresAt: s.now().Add(s.refreshTTL),
+	}
+	if err := s.refresh.Create(ctx, stored); err != nil {
+		return models.TokenPair{}, err
+	}
+
+	return models.TokenPair{
+		AccessToken:      access,
+		RefreshToken:     refresh,
+		TokenType:        "Bearer",
+		ExpiresAt:        expiresAt,
+		RefreshExpiresAt: stored.ExpiresAt,
+	}, nil
+}
@@ -0,0 +1,342 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/models"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/repository"
+)
+
+type memoryUsers struct {
+	mu     sync.Mutex
+	nextID int64
+	byID   map[int64]*models.User
+}
+
+func (m *memoryUsers) Create(ctx context.Context, user *models.User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, u := range m.byID {
+		if u.Email == user.Email {
+			return repository.ErrEmailTaken
+		}
+	}
+	m.nextID++
+	user.ID = m.nextID
+	user.CreatedAt = time.Now()
+	user.UpdatedAt = user.CreatedAt
+	copied := *user
+	m.byID[user.ID] = &copied
+	return nil
+}
+
+func (m *memoryUsers) GetByID(ctx context.Context, id int64) (*models.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if u, ok := m.byID[id]; ok {
+		copied := *u
+		return &copied, nil
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (m *memoryUsers) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, u := range m.byID {
+		if u.Email == email {
+			copied := *u
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (m *memoryUsers) UpdatePassword(ctx context.Context, id int64, passwordHash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	u, ok := m.byID[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	u.PasswordHash = passwordHash
+	return nil
+}
+
+func (m *memoryUsers) SetDisabled(ctx context.Context, id int64, disabled bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	u, ok := m.byID[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	u.DisabledAt = nil
+	if disabled {
+		now := time.Now()
+		u.DisabledAt = &now
+	}
+	return nil
+}
+
+type memoryRefreshTokens struct {
+	mu     sync.Mutex
+	nextID int64
+	byHash map[string]*models.RefreshToken
+}
+
+func (m *memoryRefreshTokens) Create(ctx context.Context, token *models.RefreshToken) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nextID++
+	token.ID = m.nextID
+	copied := *token
+	m.byHash[token.TokenHash] = &copied
+	return nil
+}
+
+func (m *memoryRefreshTokens) GetByHash(ctx context.Context, hash string) (*models.RefreshToken, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if t, ok := m.byHash[hash]; ok {
+		copied := *t
+		return &copied, nil
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (m *memoryRefreshTokens) Revoke(ctx context.Context, id int64) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, t := range m.byHash {
+		if t.ID == id && t.RevokedAt == nil {
+			now := time.Now()
+			t.RevokedAt = &now
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *memoryRefreshTokens) RevokeAllForUser(ctx context.Context, userID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	for _, t := range m.byHash {
+		if t.UserID == userID && t.RevokedAt == nil {
+			t.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+	tokens, err := NewTokenService("test-secret", time.Minute)
+	if err != nil {
+		t.Fatalf("NewTokenService() failed: %v", err)
+	}
+	svc, err := NewService(
+		&memoryUsers{byID: map[int64]*models.User{}},
+		&memoryRefreshTokens{byHash: map[string]*models.RefreshToken{}},
+		tokens, time.Hour, 4,
+	)
+	if err != nil {
+		t.Fatalf("NewService() failed: %v", err)
+	}
+	return svc
+}
+
+func validRegistration() models.RegisterRequest {
+	return models.RegisterRequest{Email: " Alice@Example.com ", Name: "Alice", Password: "password1"}
+}
+
+func TestRegister(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t)
+
+	user, pair, err := svc.Register(ctx, validRegistration())
+	if err != nil {
+		t.Fatalf("Register() failed: %v", err)
+	}
+	if user.ID == 0 || user.Email != "alice@example.com" {
+		t.Errorf("Unexpected user: %+v", user)
+	}
+	if user.PasswordHash == "" || user.PasswordHash == "password1" {
+		t.Error("Password must be stored hashed")
+	}
+	if pair.AccessToken == "" || pair.RefreshToken == "" || pair.TokenType != "Bearer" {
+		t.Errorf("Unexpected token pair: %+v", pair)
+	}
+
+	claims, err := svc.Tokens().ValidateAccessToken(pair.AccessToken)
+	if err != nil || claims.UserID != user.ID {
+		t.Errorf("Access token should identify the user, got %+v, %v", claims, err)
+	}
+
+	if _, _, err := svc.Register(ctx, validRegistration()); !errors.Is(err, repository.ErrEmailTaken) {
+		t.Errorf("Expected ErrEmailTaken, got %v", err)
+	}
+}
+
+func TestRegisterValidation(t *testing.T) {
+	svc := newTestService(t)
+	tests := []struct {
+		name string
+		req  models.RegisterRequest
+		want error
+	}{
+		{"invalid email", models.RegisterRequest{Email: "nope", Name: "Bob", Password: "password1"}, models.ErrInvalidEmail},
+		{"short name", models.RegisterRequest{Email: "bob@example.com", Name: "B", Password: "password1"}, models.ErrInvalidName},
+		{"weak password", models.RegisterRequest{Email: "bob@example.com", Name: "Bob", Password: "password"}, models.ErrWeakPassword},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := svc.Register(context.Background(), tt.req); !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestLogin(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t)
+	if _, _, err := svc.Register(ctx, validRegistration()); err != nil {
+		t.Fatalf("Register() failed: %v", err)
+	}
+
+	if _, _, err := svc.Login(ctx, models.LoginRequest{Email: "ALICE@example.com", Password: "password1"}); err != nil {
+		t.Errorf("Login() failed: %v", err)
+	}
+	if _, _, err := svc.Login(ctx, models.LoginRequest{Email: "alice@example.com", Password: "wrong1234"}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected ErrInvalidCredentials for wrong password, got %v", err)
+	}
+	if _, _, err := svc.Login(ctx, models.LoginRequest{Email: "nobody@example.com", Password: "password1"}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected ErrInvalidCredentials for unknown user, got %v", err)
+	}
+}
+
+func TestRefreshRotation(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t)
+	_, pair, err := svc.Register(ctx, validRegistration())
+	if err != nil {
+		t.Fatalf("Register() failed: %v", err)
+	}
+
+	rotated, err := svc.Refresh(ctx, pair.RefreshToken)
+	if err != nil {
+		t.Fatalf("Refresh() failed: %v", err)
+	}
+	if rotated.RefreshToken == pair.RefreshToken {
+		t.Error("Refresh must issue a new refresh token")
+	}
+
+	// Replaying the old token is rejected and revokes the rotated one too
+	if _, err := svc.Refresh(ctx, pair.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Expected ErrInvalidRefreshToken on reuse, got %v", err)
+	}
+	if _, err := svc.Refresh(ctx, rotated.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Expected all sessions revoked after reuse, got %v", err)
+	}
+
+	if _, err := svc.Refresh(ctx, "unknown"); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Expected ErrInvalidRefreshToken for unknown token, got %v", err)
+	}
+}
+
+func TestRefreshExpired(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t)
+	_, pair, err := svc.Register(ctx, validRegistration())
+	if err != nil {
+		t.Fatalf("Register() failed: %v", err)
+	}
+
+	svc.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	if _, err := svc.Refresh(ctx, pair.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Expected ErrInvalidRefreshToken for expired token, got %v", err)
+	}
+}
+
+func TestLogout(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t)
+	_, pair, err := svc.Register(ctx, validRegistration())
+	if err != nil {
+		t.Fatalf("Register() failed: %v", err)
+	}
+
+	if err := svc.Logout(ctx, pair.RefreshToken); err != nil {
+		t.Fatalf("Logout() failed: %v", err)
+	}
+	if _, err := svc.Refresh(ctx, pair.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Expected refresh after logout to fail, got %v", err)
+	}
+	if err := svc.Logout(ctx, "unknown"); err != nil {
+		t.Errorf("Logout() with unknown token should be a no-op, got %v", err)
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t)
+	_, pair, err := svc.Register(ctx, validRegistration())
+	if err != nil {
+		t.Fatalf("Register() failed: %v", err)
+	}
+
+	if err := svc.ResetPassword(ctx, "alice@example.com", "short"); !errors.Is(err, models.ErrWeakPassword) {
+		t.Errorf("Expected ErrWeakPassword, got %v", err)
+	}
+	if err := svc.ResetPassword(ctx, "nobody@example.com", "password2"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for unknown user, got %v", err)
+	}
+	if err := svc.ResetPassword(ctx, "ALICE@example.com", "password2"); err != nil {
+		t.Fatalf("ResetPassword() failed: %v", err)
+	}
+
+	if _, _, err := svc.Login(ctx, models.LoginRequest{Email: "alice@example.com", Password: "password1"}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected old password to be rejected, got %v", err)
+	}
+	if _, _, err := svc.Login(ctx, models.LoginRequest{Email: "alice@example.com", Password: "password2"}); err != nil {
+		t.Errorf("Login() with new password failed: %v", err)
+	}
+	if _, err := svc.Refresh(ctx, pair.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Expected sessions to be revoked after reset, got %v", err)
+	}
+}
+
+func TestSetDisabled(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t)
+	_, pair, err := svc.Register(ctx, validRegistration())
+	if err != nil {
+		t.Fatalf("Register() failed: %v", err)
+	}
+
+	if err := svc.SetDisabled(ctx, "alice@example.com", true); err != nil {
+		t.Fatalf("SetDisabled() failed: %v", err)
+	}
+	login := models.LoginRequest{Email: "alice@example.com", Password: "password1"}
+	if _, _, err := svc.Login(ctx, login); !errors.Is(err, ErrUserDisabled) {
+		t.Errorf("Expected ErrUserDisabled, got %v", err)
+	}
+	if _, _, err := svc.Login(ctx, models.LoginRequest{Email: "alice@example.com", Password: "wrong1234"}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected wrong password to stay ErrInvalidCredentials, got %v", err)
+	}
+	if _, err := svc.Refresh(ctx, pair.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Expected sessions to be revoked on disable, got %v", err)
+	}
+
+	if err := svc.SetDisabled(ctx, "alice@example.com", false); err != nil {
+		t.Fatalf("SetDisabled() failed: %v", err)
+	}
+	if _, _, err := svc.Login(ctx, login); err != nil {
+		t.Errorf("Login() after enabling failed: %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Token errors
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrTokenExpired = errors.New("token expired")
+)
+
+// Claims represents the claims of an access token
+type Claims struct {
+	UserID int64  `json:"uid"`
+	Email  string `json:"email"`
+	jwt.RegisteredClaims
+}
+
+// TokenService issues and validates HS256 access tokens
+type TokenService struct {
+	secret []byte
+	ttl    time.Duration
+	issuer string
+	now    func() time.Time
+}
+
+// NewTokenService creates a token service signing with secret
+func NewTokenService(secret string, ttl time.Duration) (*TokenService, error) {
+	if secret == "" {
+		return nil, errors.New("secret key cannot be empty")
+	}
+	if ttl <= 0 {
+		return nil, errors.New("token TTL must be positive")
+	}
+	return &TokenService{
+		secret: []byte(secret),
+		ttl:    ttl,
+		issuer: "sum25-go-flutter-course-backend",
+		now:    time.Now,
+	}, nil
+}
+
+// GenerateAccessToken creates a signed access token for a user
+func (s *TokenService) GenerateAccessToken(userID int64, email string) (string, time.Time, error) {
+	now := s.now()
+	expiresAt := now.Add(s.ttl)
+	claims := Claims{
+		UserID: userID,
+		Email:  email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.FormatInt(userID, 10),
+			Issuer:    s.issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to sign token: %w", err)
+	}
+	return token, expiresAt, nil
+}
+
+// ValidateAccessToken verifies the signature and expiry of an access token
+func (s *TokenService) ValidateAccessToken(tokenString string) (*Claims, error) {
+	if tokenString == "" {
+		return nil, ErrInvalidToken
+	}
+
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (any, error) {
+		if t.Method != jwt.SigningMethodHS256 {
+			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+		}
+		return s.secret, nil
+	})
+
+	var validationErr *jwt.ValidationError
+	if errors.As(err, &validationErr) && validationErr.Errors&jwt.ValidationErrorExpired != 0 {
+		return nil, ErrTokenExpired
+	}
+	if err != nil || !token.Valid || claims.UserID <= 0 || claims.Issuer != s.issuer {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// NewRefreshToken returns a random opaque refresh token and its storage hash
+func NewRefreshToken() (token, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, HashRefreshToken(token), nil
+}
+
+// HashRefreshToken returns the hex SHA-256 of a refresh token
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
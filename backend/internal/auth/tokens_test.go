@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func TestNewTokenService(t *testing.T) {
+	if _, err := NewTokenService("", time.Minute); err == nil {
+		t.Error("Expected error for empty secret")
+	}
+	if _, err := NewTokenService("secret", 0); err == nil {
+		t.Error("Expected error for zero TTL")
+	}
+}
+
+func TestAccessTokenRoundTrip(t *testing.T) {
+	svc, err := NewTokenService("test-secret", time.Minute)
+	if err != nil {
+		t.Fatalf("NewTokenService() failed: %v", err)
+	}
+
+	token, expiresAt, err := svc.GenerateAccessToken(42, "user@example.com")
+	if err != nil {
+		t.Fatalf("GenerateAccessToken() failed: %v", err)
+	}
+	if time.Until(expiresAt) > time.Minute || time.Until(expiresAt) < 50*time.Second {
+		t.Errorf("Unexpected expiry %v", expiresAt)
+	}
+
+	claims, err := svc.ValidateAccessToken(token)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken() failed: %v", err)
+	}
+	if claims.UserID != 42 || claims.Email != "user@example.com" || claims.Subject != "42" {
+		t.Errorf("Unexpected claims: %+v", claims)
+	}
+}
+
+func TestValidateAccessTokenRejects(t *testing.T) {
+	svc, _ := NewTokenService("test-secret", time.Minute)
+	other, _ := NewTokenService("other-secret", time.Minute)
+
+	foreign, _, _ := other.GenerateAccessToken(1, "a@example.com")
+	if _, err := svc.ValidateAccessToken(foreign); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected ErrInvalidToken for wrong signature, got %v", err)
+	}
+
+	if _, err := svc.ValidateAccessToken(""); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected ErrInvalidToken for empty token, got %v", err)
+	}
+
+	none := jwt.NewWithClaims(jwt.SigningMethodNone, Claims{UserID: 1})
+	unsigned, _ := none.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if _, err := svc.ValidateAccessToken(unsigned); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected ErrInvalidToken for alg none, got %v", err)
+	}
+
+	svc.now = func() time.Time { return time.Now().Add(-time.Hour) }
+	expired, _, _ := svc.GenerateAccessToken(1, "a@example.com")
+	svc.now = time.Now
+	if _, err := svc.ValidateAccessToken(expired); !errors.Is(err, ErrTokenExpired) {
+		t.Errorf("Expected ErrTokenExpired, got %v", err)
+	}
+}
+
+func TestRefreshTokenHash(t *testing.T) {
+	token, hash, err := NewRefreshToken()
+	if err != nil {
+		t.Fatalf("NewRefreshToken() failed: %v", err)
+	}
+	if len(hash) != 64 || hash != HashRefreshToken(token) {
+		t.Errorf("Expected hash to be the hex SHA-256 of the token, got %q", hash)
+	}
+
+	other, _, _ := NewRefreshToken()
+	if token == other {
+		t.Error("Refresh tokens must be unique")
+	}
+}
+
+func TestPasswordHashing(t *testing.T) {
+	hash, err := HashPassword("secret123", 4)
+	if err != nil {
+		t.Fatalf("HashPassword() failed: %v", err)
+	}
+	if !VerifyPassword("secret123", hash) {
+		t.Error("Expected password to match its hash")
+	}
+	if VerifyPassword("wrong123", hash) {
+		t.Error("Expected wrong password to be rejected")
+	}
+	if _, err := HashPassword("", 4); err == nil {
+		t.Error("Expected error for empty password")
+	}
+}
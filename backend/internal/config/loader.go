@@ -0,0 +1,230 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// lookupFunc looks up an environment variable, os.LookupEnv in production code
+type lookupFunc func(key string) (string, bool)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// defaultOf returns the default tag of a Config field, it panics if the field has none
+func defaultOf(name string) string {
+	field, ok := reflect.TypeOf(Config{}).FieldByName(name)
+	if !ok {
+		panic("config: no field " + name)
+	}
+	def, ok := field.Tag.Lookup("default")
+	if !ok {
+		panic("config: field " + name + " has no default")
+	}
+	return def
+}
+
+// rawFlag keeps the text of a flag until it is applied with setField.
+// Bool fields are boolean flags, so a bare -tls-enabled means true.
+type rawFlag struct {
+	value  string
+	isBool bool
+}
+
+func (f *rawFlag) String() string     { return f.value }
+func (f *rawFlag) Set(s string) error { f.value = s; return nil }
+func (f *rawFlag) IsBoolFlag() bool   { return f.isBool }
+
+// load fills the tagged fields of dst layer by layer and returns the
+// positional arguments left after flag parsing
+func load(dst any, args []string, lookup lookupFunc) ([]string, error) {
+	v := reflect.ValueOf(dst).Elem()
+	t := v.Type()
+
+	var errs []error
+
+	// Layer 1: defaults
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if def, ok := field.Tag.Lookup("default"); ok {
+			if err := setField(v.Field(i), def); err != nil {
+				errs = append(errs, fmt.Errorf("default for %s: %w", field.Name, err))
+			}
+		}
+	}
+
+	// Flags are parsed first so that -config can select the file,
+	// but their values are applied last
+	fs := flag.NewFlagSet(filepath.Base(os.Args[0]), flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	configFile := fs.String("config", "", "path to a YAML or JSON config file")
+	flagValues := map[string]*rawFlag{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if name := field.Tag.Get("flag"); name != "" {
+			value := &rawFlag{isBool: field.Type.Kind() == reflect.Bool}
+			fs.Var(value, name, field.Tag.Get("usage"))
+			flagValues[name] = value
+		}
+	}
+	if err := fs.Parse(args); err != nil {
+		var usage strings.Builder
+		fs.SetOutput(&usage)
+		fs.PrintDefaults()
+		return nil, fmt.Errorf("%w\n\nFlags:\n%s", err, usage.String())
+	}
+	setFlags := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+
+	// Layer 2: config file
+	path := *configFile
+	if path == "" {
+		path, _ = lookup("CONFIG_FILE")
+	}
+	if path != "" {
+		errs = append(errs, loadFile(v, path)...)
+	}
+
+	// Layer 3: environment variables
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := field.Tag.Get("env")
+		if key == "" {
+			continue
+		}
+		if value, ok := lookup(key); ok {
+			if err := setField(v.Field(i), value); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			}
+		}
+	}
+
+	// Layer 4: command-line flags
+	for i := 0; i < t.NumField(); i++ {
+		name := t.Field(i).Tag.Get("flag")
+		if name == "" || !setFlags[name] {
+			continue
+		}
+		if err := setField(v.Field(i), flagValues[name].value); err != nil {
+			errs = append(errs, fmt.Errorf("-%s: %w", name, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, &ValidationError{Errors: errs}
+	}
+	return fs.Args(), nil
+}
+
+// loadFile applies the values of a YAML or JSON file, JSON being a subset of YAML
+func loadFile(v reflect.Value, path string) []error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return []error{fmt.Errorf("config file: %w", err)}
+	}
+
+	var values map[string]any
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return []error{fmt.Errorf("config file %s: %w", path, err)}
+	}
+
+	var errs []error
+	known := map[string]bool{}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		key := t.Field(i).Tag.Get("yaml")
+		if key == "" || key == "-" {
+			continue
+		}
+		known[key] = true
+		raw, ok := values[key]
+		if !ok || raw == nil {
+			continue
+		}
+		if err := setFieldFromFile(v.Field(i), raw); err != nil {
+			errs = append(errs, fmt.Errorf("config file %s: %s: %w", path, key, err))
+		}
+	}
+
+	for key := range values {
+		if !known[key] {
+			errs = append(errs, fmt.Errorf("config file %s: unknown key %q", path, key))
+		}
+	}
+	return errs
+}
+
+// setFieldFromFile assigns a decoded YAML value, accepting sequences for list fields
+func setFieldFromFile(field reflect.Value, raw any) error {
+	if items, ok := raw.([]any); ok {
+		if field.Kind() != reflect.Slice {
+			return errors.New("unexpected list")
+		}
+		parts := make([]string, 0, len(items))
+		for _, item := range items {
+			parts = append(parts, fmt.Sprint(item))
+		}
+		return setField(field, strings.Join(parts, ","))
+	}
+	return setField(field, fmt.Sprint(raw))
+}
+
+// setField parses raw according to the field type
+func setField(field reflect.Value, raw string) error {
+	raw = strings.TrimSpace(raw)
+
+	if field.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", raw)
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		field.SetInt(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		field.SetBool(b)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		field.SetFloat(f)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported list type %s", field.Type())
+		}
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
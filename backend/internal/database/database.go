@@ -0,0 +1,96 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/config"
+)
+
+// DriverName is the database/sql driver used for Postgres
+const DriverName = "pgx"
+
+// maxRetryDelay caps the exponential backoff between connection attempts
+const maxRetryDelay = 30 * time.Second
+
+// Config holds database configuration
+type Config struct {
+	URL             string
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	ConnectRetries  int
+	RetryDelay      time.Duration
+}
+
+// ConfigFromApp extracts the database settings from the application configuration
+func ConfigFromApp(cfg *config.Config) Config {
+	return Config{
+		URL:             cfg.DatabaseURL,
+		MaxOpenConns:    cfg.DBMaxOpenConns,
+		MaxIdleConns:    cfg.DBMaxIdleConns,
+		ConnMaxLifetime: cfg.DBConnMaxLifetime,
+		ConnMaxIdleTime: cfg.DBConnMaxIdleTime,
+		ConnectRetries:  cfg.DBConnectRetries,
+		RetryDelay:      cfg.DBConnectRetryDelay,
+	}
+}
+
+// Open creates a pooled connection and waits until the database answers a ping.
+// Failed pings are retried ConnectRetries times with exponential backoff,
+// which lets the server start while Postgres is still booting.
+// Every query is traced as a child span of the request in its context.
+func Open(ctx context.Context, cfg Config) (*sql.DB, error) {
+	connConfig, err := pgx.ParseConfig(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	connConfig.Tracer = queryTracer{}
+	db := stdlib.OpenDB(*connConfig)
+
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	delay := cfg.RetryDelay
+	for attempt := 0; ; attempt++ {
+		err = db.PingContext(ctx)
+		if err == nil {
+			return db, nil
+		}
+		if attempt >= cfg.ConnectRetries {
+			break
+		}
+
+		slog.Warn("database not ready, retrying",
+			slog.Int("attempt", attempt+1),
+			slog.Duration("delay", delay),
+			slog.String("error", err.Error()))
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			db.Close()
+			return nil, fmt.Errorf("failed to connect to database: %w", ctx.Err())
+		}
+		delay = min(delay*2, maxRetryDelay)
+	}
+
+	db.Close()
+	return nil, fmt.Errorf("failed to connect to database after %d attempts: %w", cfg.ConnectRetries+1, err)
+}
+
+// Close closes the database connection pool
+func Close(db *sql.DB) error {
+	if db == nil {
+		return fmt.Errorf("database connection cannot be nil")
+	}
+	return db.Close()
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/apierror"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/auth"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/middleware"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/models"
+)
+
+// AuthHandler serves the /auth endpoints
+type AuthHandler struct {
+	service *auth.Service
+}
+
+// NewAuthHandler creates a new AuthHandler
+func NewAuthHandler(service *auth.Service) *AuthHandler {
+	return &AuthHandler{service: service}
+}
+
+// Register creates an account and returns a token pair
+func (h *AuthHandler) Register(c *gin.Context) {
+	var req models.RegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.RespondError(c, apierror.Binding(err))
+		return
+	}
+
+	user, tokens, err := h.service.Register(c.Request.Context(), req)
+	if err != nil {
+		middleware.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.AuthResponse{User: user, Tokens: tokens})
+}
+
+// Login checks credentials and returns a token pair
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req models.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.RespondError(c, apierror.Binding(err))
+		return
+	}
+
+	user, tokens, err := h.service.Login(c.Request.Context(), req)
+	if err != nil {
+		middleware.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.AuthResponse{User: user, Tokens: tokens})
+}
+
+// Refresh exchanges a refresh token for a new token pair
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req models.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.RespondError(c, apierror.Binding(err))
+		return
+	}
+
+	tokens, err := h.service.Refresh(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		middleware.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// Logout revokes a refresh token
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req models.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.RespondError(c, apierror.Binding(err))
+		return
+	}
+
+	if err := h.service.Logout(c.Request.Context(), req.RefreshToken); err != nil {
+		middleware.RespondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Me returns the authenticated user
+func (h *AuthHandler) Me(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		middleware.RespondError(c, apierror.Unauthorized("not authenticated"))
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
@@ -1,23 +1,105 @@
 package handlers
 
 import (
+	"log/slog"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/apierror"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/buildinfo"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/health"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/middleware"
 )
 
+const serviceName = "sum25-go-flutter-course-backend"
+
+// HealthResponse is returned by the legacy /health endpoint
+type HealthResponse struct {
+	Status  string `json:"status"`
+	Service string `json:"service"`
+	Version string `json:"version"`
+}
+
+// LivenessResponse is returned by /livez
+type LivenessResponse struct {
+	Status  string         `json:"status"`
+	Service string         `json:"service"`
+	Build   buildinfo.Info `json:"build"`
+}
+
+// ReadinessResponse is returned by /readyz with the status of every check by name.
+// Failure details stay in the server log, they may reveal internals such as driver errors.
+type ReadinessResponse struct {
+	Status  string            `json:"status"`
+	Service string            `json:"service"`
+	Build   buildinfo.Info    `json:"build"`
+	Checks  map[string]string `json:"checks"`
+}
+
+// PingResponse is returned by /api/v1/ping
+type PingResponse struct {
+	Message string `json:"message"`
+}
+
 // HealthCheck returns server health status
 func HealthCheck(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
-		"status":  "healthy",
-		"service": "sum25-go-flutter-course-backend",
-		"version": "1.0.0",
+	c.JSON(http.StatusOK, HealthResponse{
+		Status:  "healthy",
+		Service: serviceName,
+		Version: buildinfo.Get().Version,
+	})
+}
+
+// Liveness reports that the process is up, it never touches dependencies
+func Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, LivenessResponse{
+		Status:  health.StatusPass,
+		Service: serviceName,
+		Build:   buildinfo.Get(),
 	})
 }
 
+// Readiness runs every registered check and returns 503 if any of them fails
+func Readiness(registry *health.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report := registry.Run(c.Request.Context())
+
+		status := http.StatusOK
+		if !report.Healthy() {
+			status = http.StatusServiceUnavailable
+		}
+
+		checks := make(map[string]string, len(report.Checks))
+		for name, result := range report.Checks {
+			checks[name] = result.Status
+			if result.Status != health.StatusPass {
+				slog.WarnContext(c.Request.Context(), "readiness check failed",
+					slog.String("check", name),
+					slog.String("error", result.Error),
+					slog.String("duration", result.Duration))
+			}
+		}
+
+		c.JSON(status, ReadinessResponse{
+			Status:  report.Status,
+			Service: serviceName,
+			Build:   buildinfo.Get(),
+			Checks:  checks,
+		})
+	}
+}
+
 // Ping returns a simple pong response
 func Ping(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
-		"message": "pong",
-	})
+	c.JSON(http.StatusOK, PingResponse{Message: "pong"})
+}
+
+// NotFound answers requests that match no route
+func NotFound(c *gin.Context) {
+	middleware.RespondError(c, apierror.NotFound("route not found"))
+}
+
+// MethodNotAllowed answers requests whose path exists with another method
+func MethodNotAllowed(c *gin.Context) {
+	middleware.RespondError(c, apierror.New(http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "method not allowed"))
 }
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/health"
+)
+
+func TestReadinessHidesCheckErrors(t *testing.T) {
+	registry := health.NewRegistry()
+	registry.Register("database", 0, health.CheckerFunc(func(ctx context.Context) error {
+		return errors.New("dial tcp 10.0.0.5:5432: connect: connection refused")
+	}))
+	registry.Register("disk", 0, health.CheckerFunc(func(ctx context.Context) error { return nil }))
+
+	router := gin.New()
+	router.GET("/readyz", Readiness(registry))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503, got %d", w.Code)
+	}
+	if strings.Contains(w.Body.String(), "10.0.0.5") {
+		t.Errorf("Response leaks the check error: %s", w.Body.String())
+	}
+
+	var body ReadinessResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if body.Checks["database"] != health.StatusFail || body.Checks["disk"] != health.StatusPass {
+		t.Errorf("Unexpected checks %v", body.Checks)
+	}
+}
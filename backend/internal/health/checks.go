@@ -0,0 +1,52 @@
+package health
+
+import (
+	"context"
+	"fmt"
+)
+
+// Pinger is implemented by *sql.DB
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// VersionReader reports the schema version applied to the database
+type VersionReader interface {
+	Version(ctx context.Context) (int64, error)
+}
+
+// DatabaseCheck fails when the database does not answer a ping
+func DatabaseCheck(db Pinger) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		return db.PingContext(ctx)
+	})
+}
+
+// MigrationCheck fails when the database schema version differs from the
+// version the binary was built for
+func MigrationCheck(reader VersionReader, expected int64) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		current, err := reader.Version(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to read schema version: %w", err)
+		}
+		if current != expected {
+			return fmt.Errorf("schema version %d, expected %d", current, expected)
+		}
+		return nil
+	})
+}
+
+// DiskSpaceCheck fails when the filesystem holding path has less than minFree bytes available
+func DiskSpaceCheck(path string, minFree uint64) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		free, err := freeDiskSpace(path)
+		if err != nil {
+			return err
+		}
+		if free < minFree {
+			return fmt.Errorf("only %d MiB free on %s, need %d MiB", free>>20, path, minFree>>20)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,118 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeVersionReader struct {
+	version int64
+	err     error
+}
+
+func (f fakeVersionReader) Version(ctx context.Context) (int64, error) {
+	return f.version, f.err
+}
+
+type fakePinger struct {
+	err error
+}
+
+func (f fakePinger) PingContext(ctx context.Context) error {
+	return f.err
+}
+
+func TestRegistryAllPass(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("db", time.Second, DatabaseCheck(fakePinger{}))
+	registry.Register("schema", time.Second, MigrationCheck(fakeVersionReader{version: 3}, 3))
+
+	report := registry.Run(context.Background())
+	if !report.Healthy() {
+		t.Fatalf("Expected healthy report, got %+v", report)
+	}
+	if len(report.Checks) != 2 {
+		t.Errorf("Expected 2 check results, got %d", len(report.Checks))
+	}
+	for name, result := range report.Checks {
+		if result.Status != StatusPass || result.Duration == "" {
+			t.Errorf("Unexpected result for %s: %+v", name, result)
+		}
+	}
+}
+
+func TestRegistryFailures(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("db", time.Second, DatabaseCheck(fakePinger{err: errors.New("connection refused")}))
+	registry.Register("schema", time.Second, MigrationCheck(fakeVersionReader{version: 2}, 3))
+	registry.Register("ok", time.Second, CheckerFunc(func(ctx context.Context) error { return nil }))
+
+	report := registry.Run(context.Background())
+	if report.Healthy() {
+		t.Fatal("Expected unhealthy report")
+	}
+	if got := report.Checks["db"].Error; got != "connection refused" {
+		t.Errorf("Unexpected db error %q", got)
+	}
+	if got := report.Checks["schema"].Error; got != "schema version 2, expected 3" {
+		t.Errorf("Unexpected schema error %q", got)
+	}
+	if report.Checks["ok"].Status != StatusPass {
+		t.Error("Passing check should still be reported as pass")
+	}
+}
+
+func TestRegistryTimeout(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("slow", 20*time.Millisecond, CheckerFunc(func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	}))
+
+	start := time.Now()
+	report := registry.Run(context.Background())
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Run() should not wait for a timed out check, took %s", elapsed)
+	}
+	if result := report.Checks["slow"]; result.Status != StatusFail || result.Error != "timed out after 20ms" {
+		t.Errorf("Expected timeout failure, got %+v", result)
+	}
+}
+
+func TestRegistryPanic(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("panics", time.Second, CheckerFunc(func(ctx context.Context) error {
+		panic("boom")
+	}))
+
+	report := registry.Run(context.Background())
+	if report.Checks["panics"].Status != StatusFail {
+		t.Error("Panicking check should fail")
+	}
+}
+
+func TestRegisterReplaces(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("db", 0, CheckerFunc(func(ctx context.Context) error { return errors.New("down") }))
+	registry.Register("db", 0, CheckerFunc(func(ctx context.Context) error { return nil }))
+
+	report := registry.Run(context.Background())
+	if !report.Healthy() || len(report.Checks) != 1 {
+		t.Errorf("Expected replaced check to pass, got %+v", report)
+	}
+}
+
+func TestDiskSpaceCheck(t *testing.T) {
+	ctx := context.Background()
+	if err := DiskSpaceCheck(t.TempDir(), 0).Check(ctx); err != nil {
+		t.Errorf("Expected disk check with no minimum to pass, got %v", err)
+	}
+	if err := DiskSpaceCheck(t.TempDir(), 1<<62).Check(ctx); err == nil {
+		t.Error("Expected disk check to fail with an impossible minimum")
+	}
+	if err := DiskSpaceCheck("/does/not/exist", 0).Check(ctx); err == nil {
+		t.Error("Expected disk check to fail for a missing path")
+	}
+}
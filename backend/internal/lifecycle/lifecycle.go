@@ -0,0 +1,127 @@
+// Package lifecycle coordinates the graceful shutdown of the server: a drain
+// phase during which readiness fails, followed by the registered cleanup hooks
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrShutdownTimeout is returned by Shutdown when the context expires before
+// every hook has finished
+var ErrShutdownTimeout = errors.New("shutdown timed out")
+
+// ErrDraining is reported by the readiness check once draining has started
+var ErrDraining = errors.New("server is shutting down")
+
+// Hook releases a resource. It should return promptly once ctx is done.
+type Hook func(ctx context.Context) error
+
+type namedHook struct {
+	name string
+	hook Hook
+}
+
+// Manager holds the shutdown hooks and the draining state
+type Manager struct {
+	mu       sync.Mutex
+	hooks    []namedHook
+	draining atomic.Bool
+	logger   *slog.Logger
+}
+
+// New creates a manager logging to logger
+func New(logger *slog.Logger) *Manager {
+	return &Manager{logger: logger}
+}
+
+// Register adds a cleanup hook. Hooks run in reverse registration order, so
+// a subsystem is stopped before the dependencies registered before it.
+func (m *Manager) Register(name string, hook Hook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, namedHook{name: name, hook: hook})
+}
+
+// Draining reports whether shutdown has started
+func (m *Manager) Draining() bool {
+	return m.draining.Load()
+}
+
+// ReadinessCheck fails once draining has started, so load balancers stop
+// routing new traffic while in-flight requests complete
+func (m *Manager) ReadinessCheck(ctx context.Context) error {
+	if m.Draining() {
+		return ErrDraining
+	}
+	return nil
+}
+
+// Drain marks the server as draining and waits for delay, giving load
+// balancers time to notice the failing readiness check. It returns early
+// when ctx is done.
+func (m *Manager) Drain(ctx context.Context, delay time.Duration) {
+	m.draining.Store(true)
+	m.logger.Info("draining", slog.Duration("delay", delay))
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
+
+// Shutdown runs the hooks in reverse registration order. Failing hooks do
+// not stop the others, their errors are joined. When ctx expires the running
+// hook is abandoned, the remaining ones are skipped and the returned error
+// wraps ErrShutdownTimeout.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.draining.Store(true)
+
+	m.mu.Lock()
+	hooks := make([]namedHook, len(m.hooks))
+	copy(hooks, m.hooks)
+	m.mu.Unlock()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		h := hooks[i]
+		start := time.Now()
+
+		done := make(chan error, 1)
+		go func() { done <- h.hook(ctx) }()
+
+		// A hook that gives up with ctx.Err() races with ctx.Done(), either way the deadline hit
+		var err error
+		timedOut := false
+		select {
+		case err = <-done:
+			timedOut = err != nil && ctx.Err() != nil
+		case <-ctx.Done():
+			timedOut = true
+		}
+
+		if timedOut {
+			skipped := make([]string, 0, i)
+			for j := i - 1; j >= 0; j-- {
+				skipped = append(skipped, hooks[j].name)
+			}
+			m.logger.Error("shutdown timed out", slog.String("hook", h.name), slog.Any("skipped", skipped))
+			errs = append(errs, fmt.Errorf("%w waiting for %s", ErrShutdownTimeout, h.name))
+			return errors.Join(errs...)
+		}
+		if err != nil {
+			m.logger.Error("shutdown hook failed", slog.String("hook", h.name), slog.String("error", err.Error()))
+			errs = append(errs, fmt.Errorf("%s: %w", h.name, err))
+			continue
+		}
+		m.logger.Info("shutdown hook done", slog.String("hook", h.name), slog.Duration("took", time.Since(start)))
+	}
+	return errors.Join(errs...)
+}
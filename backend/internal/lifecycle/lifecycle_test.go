@@ -0,0 +1,124 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newManager() *Manager {
+	return New(slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestShutdownRunsHooksInReverseOrder(t *testing.T) {
+	m := newManager()
+	var order []string
+	for _, name := range []string{"tracing", "database", "http"} {
+		m.Register(name, func(ctx context.Context) error {
+			order = append(order, name)
+			return nil
+		})
+	}
+
+	if err := m.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() failed: %v", err)
+	}
+	if got := strings.Join(order, ","); got != "http,database,tracing" {
+		t.Errorf("Expected hooks in reverse order, got %s", got)
+	}
+	if !m.Draining() {
+		t.Error("Expected manager to be draining after shutdown")
+	}
+}
+
+func TestShutdownCollectsErrors(t *testing.T) {
+	m := newManager()
+	ran := false
+	m.Register("first", func(ctx context.Context) error {
+		ran = true
+		return nil
+	})
+	m.Register("broken", func(ctx context.Context) error {
+		return errors.New("close failed")
+	})
+
+	err := m.Shutdown(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "broken: close failed") {
+		t.Errorf("Expected hook error, got %v", err)
+	}
+	if errors.Is(err, ErrShutdownTimeout) {
+		t.Error("A failing hook is not a timeout")
+	}
+	if !ran {
+		t.Error("A failing hook should not stop the remaining hooks")
+	}
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	m := newManager()
+	skippedRan := false
+	m.Register("skipped", func(ctx context.Context) error {
+		skippedRan = true
+		return nil
+	})
+	m.Register("stuck", func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := m.Shutdown(ctx)
+	if !errors.Is(err, ErrShutdownTimeout) {
+		t.Errorf("Expected ErrShutdownTimeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Shutdown() should give up on a stuck hook, took %s", elapsed)
+	}
+	if skippedRan {
+		t.Error("Hooks after a timeout should be skipped")
+	}
+}
+
+func TestDrainFailsReadiness(t *testing.T) {
+	m := newManager()
+	if err := m.ReadinessCheck(context.Background()); err != nil {
+		t.Fatalf("Expected ready before draining, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	m.Drain(ctx, time.Hour)
+	if time.Since(start) > time.Second {
+		t.Error("Drain() should return when the context is done")
+	}
+
+	if err := m.ReadinessCheck(context.Background()); !errors.Is(err, ErrDraining) {
+		t.Errorf("Expected ErrDraining, got %v", err)
+	}
+}
+
+func TestShutdownTimeoutReturnedByHook(t *testing.T) {
+	m := newManager()
+	m.Register("cooperative", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	// Repeat so both sides of the race between the hook result and ctx.Done() are covered
+	for i := 0; i < 20; i++ {
+		if err := m.Shutdown(ctx); !errors.Is(err, ErrShutdownTimeout) {
+			t.Fatalf("Expected ErrShutdownTimeout, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/tracing"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ContextHandler adds the request ID and the trace and span IDs found in the
+// record's context, so every *Context log call can be correlated with the
+// request and its trace
+type ContextHandler struct {
+	slog.Handler
+}
+
+// NewContextHandler wraps h
+func NewContextHandler(h slog.Handler) *ContextHandler {
+	return &ContextHandler{Handler: h}
+}
+
+// Handle adds the correlation attributes the record does not carry yet
+func (h *ContextHandler) Handle(ctx context.Context, r slog.Record) error {
+	present := map[string]bool{}
+	r.Attrs(func(a slog.Attr) bool {
+		present[a.Key] = true
+		return true
+	})
+
+	add := func(key, value string) {
+		if value != "" && !present[key] {
+			r.AddAttrs(slog.String(key, value))
+		}
+	}
+	add("request_id", tracing.RequestIDFromContext(ctx))
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		add("trace_id", sc.TraceID().String())
+		add("span_id", sc.SpanID().String())
+	}
+
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs keeps the wrapper around the derived handler
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup keeps the wrapper around the derived handler
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithGroup(name)}
+}
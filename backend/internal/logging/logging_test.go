@@ -0,0 +1,64 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/tracing"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		input string
+		want  slog.Level
+		err   bool
+	}{
+		{"debug", slog.LevelDebug, false},
+		{"", slog.LevelInfo, false},
+		{"WARNING", slog.LevelWarn, false},
+		{"error", slog.LevelError, false},
+		{"verbose", slog.LevelInfo, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.input)
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("ParseLevel(%q) = %v, %v", tt.input, got, err)
+		}
+	}
+}
+
+func TestContextHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, "info")
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(
+		tracing.WithRequestID(context.Background(), "req-1"),
+		trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}),
+	)
+
+	logger.With("component", "test").InfoContext(ctx, "hello", "request_id", "explicit")
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Log output is not JSON: %v", err)
+	}
+	if record["request_id"] != "explicit" {
+		t.Errorf("Explicit request_id should win, got %v", record["request_id"])
+	}
+	if record["trace_id"] != traceID.String() || record["span_id"] != spanID.String() {
+		t.Errorf("Expected trace and span IDs, got %v", record)
+	}
+	if record["component"] != "test" {
+		t.Error("Expected attributes of the derived logger to be kept")
+	}
+}
@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"database/sql"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestHandler(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	m := New()
+	if err := m.RegisterDB(db, "test"); err != nil {
+		t.Fatalf("RegisterDB() failed: %v", err)
+	}
+	if err := m.RegisterDB(db, "test"); err == nil {
+		t.Error("Expected registering the same database twice to fail")
+	}
+	m.RequestsTotal.WithLabelValues("GET", "/ping", "200").Inc()
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", w.Code)
+	}
+
+	body, _ := io.ReadAll(w.Body)
+	for _, want := range []string{
+		`course_http_requests_total{method="GET",route="/ping",status="200"} 1`,
+		"course_http_requests_in_flight 0",
+		"go_goroutines",
+		`go_sql_open_connections{db_name="test"}`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("Expected metrics output to contain %q", want)
+		}
+	}
+}
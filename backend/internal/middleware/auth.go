@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/apierror"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/auth"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/models"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/repository"
+)
+
+// UserKey is the gin.Context key holding the authenticated *models.User
+const UserKey = "user"
+
+// UserLoader loads the user referenced by an access token
+type UserLoader interface {
+	GetByID(ctx context.Context, id int64) (*models.User, error)
+}
+
+// RequireAuth middleware rejects requests without a valid bearer access token
+// and stores the authenticated user in the context for downstream handlers
+func RequireAuth(tokens *auth.TokenService, users UserLoader) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scheme, token, ok := strings.Cut(c.GetHeader("Authorization"), " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+			c.Header("WWW-Authenticate", `Bearer realm="api"`)
+			RespondError(c, apierror.Unauthorized("missing bearer token"))
+			return
+		}
+
+		claims, err := tokens.ValidateAccessToken(strings.TrimSpace(token))
+		if err != nil {
+			c.Header("WWW-Authenticate", `Bearer realm="api", error="invalid_token"`)
+			RespondError(c, err)
+			return
+		}
+
+		user, err := users.GetByID(c.Request.Context(), claims.UserID)
+		if errors.Is(err, repository.ErrNotFound) {
+			RespondError(c, apierror.Unauthorized("user no longer exists"))
+			return
+		}
+		if err != nil {
+			RespondError(c, fmt.Errorf("failed to load user: %w", err))
+			return
+		}
+		if user.Disabled() {
+			RespondError(c, auth.ErrUserDisabled)
+			return
+		}
+
+		c.Set(UserKey, user)
+		c.Next()
+	}
+}
+
+// CurrentUser returns the user stored by RequireAuth
+func CurrentUser(c *gin.Context) (*models.User, bool) {
+	value, ok := c.Get(UserKey)
+	if !ok {
+		return nil, false
+	}
+	user, ok := value.(*models.User)
+	return user, ok
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/auth"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/models"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/repository"
+)
+
+type stubUsers map[int64]*models.User
+
+func (s stubUsers) GetByID(ctx context.Context, id int64) (*models.User, error) {
+	if u, ok := s[id]; ok {
+		return u, nil
+	}
+	return nil, repository.ErrNotFound
+}
+
+func TestRequireAuth(t *testing.T) {
+	tokens, err := auth.NewTokenService("test-secret", time.Minute)
+	if err != nil {
+		t.Fatalf("NewTokenService() failed: %v", err)
+	}
+	disabledAt := time.Now()
+	users := stubUsers{
+		7: {ID: 7, Email: "user@example.com", Name: "User"},
+		9: {ID: 9, Email: "off@example.com", Name: "Off", DisabledAt: &disabledAt},
+	}
+
+	router := gin.New()
+	router.GET("/me", RequireAuth(tokens, users), func(c *gin.Context) {
+		user, ok := CurrentUser(c)
+		if !ok {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.String(http.StatusOK, user.Email)
+	})
+
+	valid, _, _ := tokens.GenerateAccessToken(7, "user@example.com")
+	deleted, _, _ := tokens.GenerateAccessToken(8, "gone@example.com")
+	disabled, _, _ := tokens.GenerateAccessToken(9, "off@example.com")
+
+	tests := []struct {
+		name   string
+		header string
+		status int
+	}{
+		{"valid token", "Bearer " + valid, http.StatusOK},
+		{"lowercase scheme", "bearer " + valid, http.StatusOK},
+		{"missing header", "", http.StatusUnauthorized},
+		{"wrong scheme", "Basic " + valid, http.StatusUnauthorized},
+		{"garbage token", "Bearer not-a-jwt", http.StatusUnauthorized},
+		{"deleted user", "Bearer " + deleted, http.StatusUnauthorized},
+		{"disabled user", "Bearer " + disabled, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/me", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, w.Code)
+			}
+			if tt.status == http.StatusOK && w.Body.String() != "user@example.com" {
+				t.Errorf("Expected authenticated user in context, got %q", w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bufferedWriter holds the response of the downstream handlers so that a
+// middleware can inspect or rewrite the complete body before it is sent.
+// A Flush call switches to pass-through mode for streaming handlers.
+type bufferedWriter struct {
+	gin.ResponseWriter
+	body        bytes.Buffer
+	status      int
+	written     bool
+	passthrough bool
+}
+
+func newBufferedWriter(w gin.ResponseWriter) *bufferedWriter {
+	return &bufferedWriter{ResponseWriter: w, status: http.StatusOK}
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	if w.passthrough {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if code > 0 && !w.written {
+		w.status = code
+	}
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {
+	if w.passthrough {
+		w.ResponseWriter.WriteHeaderNow()
+		return
+	}
+	w.written = true
+}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	if w.passthrough {
+		return w.ResponseWriter.Write(data)
+	}
+	w.written = true
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	if w.passthrough {
+		return w.ResponseWriter.WriteString(s)
+	}
+	w.written = true
+	return w.body.WriteString(s)
+}
+
+func (w *bufferedWriter) Status() int {
+	if w.passthrough {
+		return w.ResponseWriter.Status()
+	}
+	return w.status
+}
+
+func (w *bufferedWriter) Size() int {
+	if w.passthrough {
+		return w.ResponseWriter.Size()
+	}
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *bufferedWriter) Written() bool {
+	if w.passthrough {
+		return w.ResponseWriter.Written()
+	}
+	return w.written
+}
+
+// Flush sends what was buffered and stops buffering
+func (w *bufferedWriter) Flush() {
+	if !w.passthrough {
+		w.send(w.body.Bytes())
+		w.passthrough = true
+	}
+	w.ResponseWriter.Flush()
+}
+
+// send passes the buffered status and the given body to the original
+// writer. A status without a body is left for gin to write at the end of
+// the request, exactly as if no buffering had happened.
+func (w *bufferedWriter) send(body []byte) {
+	w.ResponseWriter.WriteHeader(w.status)
+	if !w.written {
+		return
+	}
+	w.ResponseWriter.WriteHeaderNow()
+	if len(body) > 0 {
+		_, _ = w.ResponseWriter.Write(body)
+	}
+}
+
+// buffer swaps c.Writer for a bufferedWriter while the handlers run and
+// hands it to finish afterwards. The original writer is restored even if a
+// handler panics, in which case the buffered response is dropped and the
+// recovery middleware answers on the original writer.
+func buffer(c *gin.Context, finish func(w *bufferedWriter)) {
+	original := c.Writer
+	w := newBufferedWriter(original)
+	c.Writer = w
+	defer func() { c.Writer = original }()
+
+	c.Next()
+
+	if w.passthrough {
+		return
+	}
+	finish(w)
+}
@@ -0,0 +1,165 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+// Supported content encodings
+const (
+	encodingBrotli = "br"
+	encodingGzip   = "gzip"
+)
+
+// CompressOptions configures Compress
+type CompressOptions struct {
+	// MinSize is the smallest body compressed, smaller bodies gain nothing
+	MinSize int
+	// ContentTypes are the compressible media types, matched by prefix
+	ContentTypes []string
+}
+
+// DefaultCompressOptions compresses JSON, text and JavaScript bodies of at least 1 KiB
+func DefaultCompressOptions() CompressOptions {
+	return CompressOptions{
+		MinSize:      1024,
+		ContentTypes: []string{"application/json", "application/problem+json", "text/", "application/javascript", "image/svg+xml"},
+	}
+}
+
+var (
+	gzipWriters = sync.Pool{New: func() any {
+		w, _ := gzip.NewWriterLevel(io.Discard, gzip.DefaultCompression)
+		return w
+	}}
+	brotliWriters = sync.Pool{New: func() any {
+		return brotli.NewWriterLevel(io.Discard, brotli.DefaultCompression)
+	}}
+)
+
+// Compress middleware compresses response bodies with brotli or gzip,
+// whichever the client prefers in Accept-Encoding (brotli on a tie). Bodies
+// below MinSize, of other content types, already encoded or not getting
+// smaller are sent as they are.
+func Compress(opts CompressOptions) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"))
+		if encoding == "" || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+
+		buffer(c, func(w *bufferedWriter) {
+			body := w.body.Bytes()
+			header := w.Header()
+			if len(body) < opts.MinSize ||
+				w.status == http.StatusNoContent || w.status == http.StatusNotModified ||
+				header.Get("Content-Encoding") != "" ||
+				!compressible(header.Get("Content-Type"), opts.ContentTypes) {
+				w.send(body)
+				return
+			}
+
+			compressed, err := compress(encoding, body)
+			if err != nil || len(compressed) >= len(body) {
+				w.send(body)
+				return
+			}
+
+			header.Set("Content-Encoding", encoding)
+			header.Del("Content-Length")
+			w.send(compressed)
+		})
+	}
+}
+
+// negotiateEncoding picks br or gzip from an Accept-Encoding header, honoring
+// q-values, or returns an empty string when neither is acceptable
+func negotiateEncoding(acceptEncoding string) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+
+	quality := map[string]float64{}
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		quality[name] = q
+	}
+
+	pick := func(name string) float64 {
+		if q, ok := quality[name]; ok {
+			return q
+		}
+		if q, ok := quality["*"]; ok {
+			return q
+		}
+		return 0
+	}
+
+	br, gz := pick(encodingBrotli), pick(encodingGzip)
+	switch {
+	case br > 0 && br >= gz:
+		return encodingBrotli
+	case gz > 0:
+		return encodingGzip
+	default:
+		return ""
+	}
+}
+
+func compressible(contentType string, types []string) bool {
+	contentType = strings.ToLower(contentType)
+	for _, prefix := range types {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// compress encodes body with a pooled writer
+func compress(encoding string, body []byte) ([]byte, error) {
+	var out bytes.Buffer
+	switch encoding {
+	case encodingBrotli:
+		w := brotliWriters.Get().(*brotli.Writer)
+		defer brotliWriters.Put(w)
+		w.Reset(&out)
+		if _, err := w.Write(body); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+	default:
+		w := gzipWriters.Get().(*gzip.Writer)
+		defer gzipWriters.Put(w)
+		w.Reset(&out)
+		if _, err := w.Write(body); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+var largeBody = strings.Repeat(`{"title":"compressible"}`, 200)
+
+func newCompressedRouter() *gin.Engine {
+	router := gin.New()
+	router.Use(Compress(DefaultCompressOptions()))
+	router.GET("/large", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", []byte(largeBody))
+	})
+	router.GET("/small", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+	router.GET("/image", func(c *gin.Context) {
+		c.Data(http.StatusOK, "image/png", []byte(largeBody))
+	})
+	router.DELETE("/empty", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return router
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", ""},
+		{"gzip", "gzip"},
+		{"gzip, deflate, br", "br"},
+		{"br;q=0.5, gzip", "gzip"},
+		{"gzip;q=0, br;q=0", ""},
+		{"*", "br"},
+		{"identity", ""},
+		{"GZIP;q=0.8, *;q=0.1", "gzip"},
+	}
+
+	for _, tt := range tests {
+		if got := negotiateEncoding(tt.header); got != tt.want {
+			t.Errorf("negotiateEncoding(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestCompress(t *testing.T) {
+	router := newCompressedRouter()
+
+	tests := []struct {
+		name     string
+		encoding string
+		decode   func(io.Reader) (io.Reader, error)
+	}{
+		{"gzip", "gzip", func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) }},
+		{"brotli", "br", func(r io.Reader) (io.Reader, error) { return brotli.NewReader(r), nil }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/large", nil)
+			req.Header.Set("Accept-Encoding", tt.encoding)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if got := w.Header().Get("Content-Encoding"); got != tt.encoding {
+				t.Fatalf("Expected Content-Encoding %q, got %q", tt.encoding, got)
+			}
+			if w.Body.Len() >= len(largeBody) {
+				t.Errorf("Expected a smaller body, got %d bytes", w.Body.Len())
+			}
+			reader, err := tt.decode(bytes.NewReader(w.Body.Bytes()))
+			if err != nil {
+				t.Fatalf("Failed to decode body: %v", err)
+			}
+			decoded, _ := io.ReadAll(reader)
+			if string(decoded) != largeBody {
+				t.Error("Decoded body does not match the original")
+			}
+			if w.Header().Get("Vary") != "Accept-Encoding" {
+				t.Errorf("Expected Vary: Accept-Encoding, got %q", w.Header().Get("Vary"))
+			}
+		})
+	}
+}
+
+func TestCompressSkips(t *testing.T) {
+	router := newCompressedRouter()
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		encoding string
+		status   int
+	}{
+		{"below threshold", http.MethodGet, "/small", "gzip", http.StatusOK},
+		{"not compressible", http.MethodGet, "/image", "gzip", http.StatusOK},
+		{"not accepted", http.MethodGet, "/large", "", http.StatusOK},
+		{"no content", http.MethodDelete, "/empty", "gzip", http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.encoding != "" {
+				req.Header.Set("Accept-Encoding", tt.encoding)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, w.Code)
+			}
+			if got := w.Header().Get("Content-Encoding"); got != "" {
+				t.Errorf("Expected no Content-Encoding, got %q", got)
+			}
+		})
+	}
+}
+
+func TestCompressRecoversPanics(t *testing.T) {
+	router := gin.New()
+	router.Use(Recovery(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	router.Use(Compress(DefaultCompressOptions()))
+	router.GET("/panic", func(c *gin.Context) {
+		c.String(http.StatusOK, "partial")
+		panic("boom")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError || strings.Contains(w.Body.String(), "partial") {
+		t.Errorf("Expected the buffered response to be replaced by a 500, got %d %q", w.Code, w.Body.String())
+	}
+}
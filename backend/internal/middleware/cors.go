@@ -1,22 +1,168 @@
 package middleware
 
 import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/apierror"
 )
 
-// CORS middleware to handle Cross-Origin Resource Sharing
-func CORS() gin.HandlerFunc {
+// CORSOptions configures the CORS middleware
+type CORSOptions struct {
+	// AllowedOrigins lists exact origins ("https://app.example.com"),
+	// wildcard subdomains ("https://*.example.com") or "*" for any origin
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	// MaxAge tells browsers how long a preflight response may be cached
+	MaxAge time.Duration
+	// Routes overrides methods and headers for specific path prefixes,
+	// the longest matching prefix wins
+	Routes []CORSRoute
+}
+
+// CORSRoute overrides the allowed methods and headers below a path prefix
+type CORSRoute struct {
+	PathPrefix     string
+	AllowedMethods []string
+	AllowedHeaders []string
+}
+
+// DefaultCORSOptions returns the options used by the API for the given allowed origins
+func DefaultCORSOptions(origins []string) CORSOptions {
+	return CORSOptions{
+		AllowedOrigins:   NormalizeOrigins(origins),
+		AllowedMethods:   []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions},
+		AllowedHeaders:   []string{"Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Authorization", "Accept", "Origin", "Cache-Control", "X-Requested-With", RequestIDHeader, "traceparent", "tracestate"},
+		ExposedHeaders:   []string{RequestIDHeader, "traceparent"},
+		AllowCredentials: true,
+		MaxAge:           12 * time.Hour,
+	}
+}
+
+// NormalizeOrigins trims whitespace and trailing slashes and drops empty entries
+func NormalizeOrigins(origins []string) []string {
+	var result []string
+	for _, origin := range origins {
+		origin = strings.TrimRight(strings.TrimSpace(origin), "/")
+		if origin != "" {
+			result = append(result, origin)
+		}
+	}
+	return result
+}
+
+// CORS middleware to handle Cross-Origin Resource Sharing.
+// The request origin is echoed back only when it matches the allow-list,
+// so credentials can be used together with a restricted set of origins.
+func CORS(opts CORSOptions) gin.HandlerFunc {
+	exposeHeaders := strings.Join(opts.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(opts.MaxAge / time.Second))
+
 	return gin.HandlerFunc(func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		header := c.Writer.Header()
+		header.Add("Vary", "Origin")
+
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+
+		if !originAllowed(opts.AllowedOrigins, origin) {
+			if preflight {
+				RespondError(c, apierror.New(http.StatusForbidden, apierror.CodeForbidden, "origin not allowed"))
+				return
+			}
+			c.Next()
+			return
+		}
+
+		// A bare "*" never carries credentials, browsers reject that combination
+		if slices.Contains(opts.AllowedOrigins, "*") {
+			header.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			header.Set("Access-Control-Allow-Origin", origin)
+			if opts.AllowCredentials {
+				header.Set("Access-Control-Allow-Credentials", "true")
+			}
+		}
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
+		if preflight {
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+			methods, headers := opts.routeRules(c.Request.URL.Path)
+			header.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+			header.Set("Access-Control-Allow-Headers", strings.Join(headers, ", "))
+			if opts.MaxAge > 0 {
+				header.Set("Access-Control-Max-Age", maxAge)
+			}
+			c.AbortWithStatus(http.StatusNoContent)
 			return
 		}
 
+		if exposeHeaders != "" {
+			header.Set("Access-Control-Expose-Headers", exposeHeaders)
+		}
+
 		c.Next()
 	})
 }
+
+// routeRules returns the allowed methods and headers for a request path
+func (o CORSOptions) routeRules(path string) ([]string, []string) {
+	methods, headers := o.AllowedMethods, o.AllowedHeaders
+	longest := -1
+	for _, route := range o.Routes {
+		if strings.HasPrefix(path, route.PathPrefix) && len(route.PathPrefix) > longest {
+			longest = len(route.PathPrefix)
+			methods, headers = o.AllowedMethods, o.AllowedHeaders
+			if route.AllowedMethods != nil {
+				methods = route.AllowedMethods
+			}
+			if route.AllowedHeaders != nil {
+				headers = route.AllowedHeaders
+			}
+		}
+	}
+	return methods, headers
+}
+
+// originAllowed reports whether origin matches one of the allowed patterns
+func originAllowed(allowed []string, origin string) bool {
+	for _, pattern := range allowed {
+		if pattern == "*" || strings.EqualFold(pattern, origin) {
+			return true
+		}
+		if matchWildcardOrigin(pattern, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchWildcardOrigin matches patterns like "https://*.example.com" against
+// any subdomain of example.com using the same scheme
+func matchWildcardOrigin(pattern, origin string) bool {
+	scheme, host, ok := strings.Cut(pattern, "://")
+	if !ok || !strings.HasPrefix(host, "*.") {
+		return false
+	}
+	prefix := strings.ToLower(scheme + "://")
+	suffix := strings.ToLower(host[1:])
+
+	origin = strings.ToLower(origin)
+	if !strings.HasPrefix(origin, prefix) || !strings.HasSuffix(origin, suffix) {
+		return false
+	}
+	sub := origin[len(prefix) : len(origin)-len(suffix)]
+	return sub != "" && !strings.ContainsAny(sub, "/:@")
+}
@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newCORSRouter(opts CORSOptions) *gin.Engine {
+	router := gin.New()
+	router.Use(CORS(opts))
+	router.GET("/api/v1/ping", func(c *gin.Context) {
+		c.String(http.StatusOK, "pong")
+	})
+	return router
+}
+
+func TestNormalizeOrigins(t *testing.T) {
+	got := NormalizeOrigins([]string{" http://localhost:3000", " ", "https://app.example.com/ "})
+	want := []string{"http://localhost:3000", "https://app.example.com"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NormalizeOrigins() = %v, want %v", got, want)
+	}
+}
+
+func TestCORSOriginMatching(t *testing.T) {
+	router := newCORSRouter(DefaultCORSOptions([]string{"http://localhost:3000", "https://*.example.com"}))
+
+	tests := []struct {
+		name    string
+		origin  string
+		allowed bool
+	}{
+		{"exact match", "http://localhost:3000", true},
+		{"wildcard subdomain", "https://app.example.com", true},
+		{"nested subdomain", "https://a.b.example.com", true},
+		{"bare domain not matched by wildcard", "https://example.com", false},
+		{"wrong scheme", "http://app.example.com", false},
+		{"suffix attack", "https://evilexample.com", false},
+		{"unknown origin", "http://evil.com", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/ping", nil)
+			req.Header.Set("Origin", tt.origin)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			got := w.Header().Get("Access-Control-Allow-Origin")
+			if tt.allowed && got != tt.origin {
+				t.Errorf("Expected origin %q to be echoed, got %q", tt.origin, got)
+			}
+			if !tt.allowed && got != "" {
+				t.Errorf("Expected no allow-origin header, got %q", got)
+			}
+			if w.Code != http.StatusOK {
+				t.Errorf("Expected simple request to pass through, got %d", w.Code)
+			}
+		})
+	}
+}
+
+func TestCORSSimpleRequestHeaders(t *testing.T) {
+	router := newCORSRouter(DefaultCORSOptions([]string{"http://localhost:3000"}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/ping", nil)
+	req.Header.Set("Origin", "http://localhost:3000")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if got := w.Header().Get("Access-Control-Allow-Credentials"); got != "true" {
+		t.Errorf("Expected credentials to be allowed, got %q", got)
+	}
+	if got := w.Header().Get("Access-Control-Expose-Headers"); got != RequestIDHeader+", traceparent" {
+		t.Errorf("Expected request ID and traceparent to be exposed, got %q", got)
+	}
+	if got := w.Header().Get("Vary"); got != "Origin" {
+		t.Errorf("Expected Vary: Origin, got %q", got)
+	}
+}
+
+func TestCORSPreflight(t *testing.T) {
+	opts := DefaultCORSOptions([]string{"http://localhost:3000"})
+	opts.MaxAge = 10 * time.Minute
+	opts.Routes = []CORSRoute{
+		{PathPrefix: "/api/v1/auth", AllowedMethods: []string{http.MethodPost}},
+	}
+	router := newCORSRouter(opts)
+
+	preflight := func(path, origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodOptions, path, nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := preflight("/api/v1/ping", "http://localhost:3000")
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Expected 204 for preflight, got %d", w.Code)
+	}
+	if got := w.Header().Get("Access-Control-Max-Age"); got != "600" {
+		t.Errorf("Expected max-age 600, got %q", got)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Methods"); got != "GET, POST, PUT, PATCH, DELETE, OPTIONS" {
+		t.Errorf("Unexpected allowed methods %q", got)
+	}
+
+	w = preflight("/api/v1/auth/login", "http://localhost:3000")
+	if got := w.Header().Get("Access-Control-Allow-Methods"); got != "POST" {
+		t.Errorf("Expected route override methods POST, got %q", got)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Headers"); got == "" {
+		t.Error("Expected default headers when route does not override them")
+	}
+
+	w = preflight("/api/v1/ping", "http://evil.com")
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for disallowed preflight, got %d", w.Code)
+	}
+}
+
+func TestCORSAnyOriginOmitsCredentials(t *testing.T) {
+	router := newCORSRouter(DefaultCORSOptions([]string{"*"}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/ping", nil)
+	req.Header.Set("Origin", "http://anything.test")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Expected *, got %q", got)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+		t.Errorf("Expected no credentials header with *, got %q", got)
+	}
+}
@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/apierror"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/auth"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/models"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/repository"
+)
+
+// domainError maps an error of a domain package to an API error
+type domainError struct {
+	target error
+	status int
+	code   string
+	// message replaces the error text in the response when set
+	message string
+}
+
+// domainErrors lists the domain errors with a dedicated status, the first match wins
+var domainErrors = []domainError{
+	{target: models.ErrInvalidEmail, status: http.StatusBadRequest, code: apierror.CodeValidation},
+	{target: models.ErrInvalidName, status: http.StatusBadRequest, code: apierror.CodeValidation},
+	{target: models.ErrWeakPassword, status: http.StatusBadRequest, code: apierror.CodeValidation},
+	{target: models.ErrLongPassword, status: http.StatusBadRequest, code: apierror.CodeValidation},
+	{target: auth.ErrInvalidCredentials, status: http.StatusUnauthorized, code: apierror.CodeUnauthorized},
+	{target: auth.ErrInvalidRefreshToken, status: http.StatusUnauthorized, code: apierror.CodeUnauthorized},
+	{target: auth.ErrInvalidToken, status: http.StatusUnauthorized, code: apierror.CodeUnauthorized},
+	{target: auth.ErrTokenExpired, status: http.StatusUnauthorized, code: apierror.CodeUnauthorized},
+	{target: auth.ErrUserDisabled, status: http.StatusForbidden, code: apierror.CodeForbidden},
+	{target: repository.ErrNotFound, status: http.StatusNotFound, code: apierror.CodeNotFound, message: "resource not found"},
+	{target: repository.ErrEmailTaken, status: http.StatusConflict, code: apierror.CodeConflict},
+}
+
+// toAPIError converts err to an API error. API errors are kept as they are,
+// known domain errors get their status and anything else is internal.
+func toAPIError(err error) *apierror.Error {
+	var apiErr *apierror.Error
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	for _, d := range domainErrors {
+		if errors.Is(err, d.target) {
+			message := d.message
+			if message == "" {
+				message = err.Error()
+			}
+			return &apierror.Error{Status: d.status, Code: d.code, Message: message, Err: err}
+		}
+	}
+	return apierror.From(err)
+}
+
+// RespondError aborts the request with the error envelope matching err.
+// Internal errors are attached to the context so RequestLogger logs the cause.
+func RespondError(c *gin.Context, err error) {
+	apiErr := toAPIError(err)
+	if apiErr.Status >= http.StatusInternalServerError {
+		_ = c.Error(err)
+	}
+
+	body := *apiErr
+	body.RequestID = c.GetString(RequestIDKey)
+	c.AbortWithStatusJSON(apiErr.Status, apierror.Envelope{Error: &body})
+}
+
+// ErrorHandler middleware renders errors attached with c.Error by handlers
+// that did not write a response themselves
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if c.Writer.Written() || len(c.Errors) == 0 {
+			return
+		}
+		RespondError(c, c.Errors.Last().Err)
+	}
+}
+
+// Recovery middleware turns panics into a 500 error envelope and logs the
+// panic value together with the stack trace
+func Recovery(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			logger.ErrorContext(c.Request.Context(), "panic recovered",
+				slog.String("request_id", c.GetString(RequestIDKey)),
+				slog.String("method", c.Request.Method),
+				slog.String("path", c.Request.URL.Path),
+				slog.Any("panic", recovered),
+				slog.String("stack", string(debug.Stack())),
+			)
+
+			// The client is gone, there is nobody to answer
+			if err, ok := recovered.(error); ok && isBrokenPipe(err) {
+				c.Abort()
+				return
+			}
+
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+			body := apierror.Internal(nil)
+			body.RequestID = c.GetString(RequestIDKey)
+			c.AbortWithStatusJSON(body.Status, apierror.Envelope{Error: body})
+		}()
+
+		c.Next()
+	}
+}
+
+// isBrokenPipe reports whether err comes from writing to a closed connection
+func isBrokenPipe(err error) bool {
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) {
+		return false
+	}
+	var sysErr *os.SyscallError
+	if !errors.As(opErr, &sysErr) {
+		return false
+	}
+	msg := strings.ToLower(sysErr.Error())
+	return strings.Contains(msg, "broken pipe") || strings.Contains(msg, "connection reset by peer")
+}
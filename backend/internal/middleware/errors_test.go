@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/apierror"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/auth"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/models"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/repository"
+)
+
+func decodeEnvelope(t *testing.T, w *httptest.ResponseRecorder) apierror.Error {
+	t.Helper()
+	var body struct {
+		Error apierror.Error `json:"error"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Expected JSON error envelope, got %q", w.Body.String())
+	}
+	return body.Error
+}
+
+func TestRespondError(t *testing.T) {
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set(RequestIDKey, "req-1") })
+	router.GET("/users/:id", func(c *gin.Context) {
+		RespondError(c, repository.ErrNotFound)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404, got %d", w.Code)
+	}
+	body := decodeEnvelope(t, w)
+	if body.Code != apierror.CodeNotFound || body.RequestID != "req-1" || body.Message == "" {
+		t.Errorf("Unexpected envelope %+v", body)
+	}
+}
+
+func TestToAPIError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"api error", apierror.Conflict("taken"), http.StatusConflict, apierror.CodeConflict},
+		{"validation", models.ErrWeakPassword, http.StatusBadRequest, apierror.CodeValidation},
+		{"not found", fmt.Errorf("get user: %w", repository.ErrNotFound), http.StatusNotFound, apierror.CodeNotFound},
+		{"conflict", repository.ErrEmailTaken, http.StatusConflict, apierror.CodeConflict},
+		{"disabled", auth.ErrUserDisabled, http.StatusForbidden, apierror.CodeForbidden},
+		{"unauthorized", auth.ErrInvalidCredentials, http.StatusUnauthorized, apierror.CodeUnauthorized},
+		{"expired token", auth.ErrTokenExpired, http.StatusUnauthorized, apierror.CodeUnauthorized},
+		{"unknown", errors.New("connection refused"), http.StatusInternalServerError, apierror.CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := toAPIError(tt.err)
+			if got.Status != tt.status || got.Code != tt.code {
+				t.Errorf("Expected %d %s, got %d %s", tt.status, tt.code, got.Status, got.Code)
+			}
+		})
+	}
+}
+
+func TestErrorHandler(t *testing.T) {
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.GET("/fails", func(c *gin.Context) {
+		_ = c.Error(errors.New("database is down"))
+	})
+	router.GET("/handled", func(c *gin.Context) {
+		_ = c.Error(errors.New("already answered"))
+		c.String(http.StatusAccepted, "ok")
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fails", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected 500, got %d", w.Code)
+	}
+	if body := decodeEnvelope(t, w); body.Code != apierror.CodeInternal || strings.Contains(body.Message, "database") {
+		t.Errorf("Unexpected envelope %+v", body)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/handled", nil))
+	if w.Code != http.StatusAccepted {
+		t.Errorf("Expected handler response to be kept, got %d", w.Code)
+	}
+}
+
+func TestRecovery(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set(RequestIDKey, "req-2") })
+	router.Use(Recovery(logger))
+	router.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected 500, got %d", w.Code)
+	}
+	if body := decodeEnvelope(t, w); body.Code != apierror.CodeInternal || body.RequestID != "req-2" {
+		t.Errorf("Unexpected envelope %+v", body)
+	}
+
+	var record map[string]any
+	if err := json.Unmarshal(logs.Bytes(), &record); err != nil {
+		t.Fatalf("Expected a JSON log record, got %q", logs.String())
+	}
+	if record["panic"] != "boom" || !strings.Contains(record["stack"].(string), "errors_test.go") {
+		t.Errorf("Expected panic value and stack in log, got %v", record)
+	}
+}
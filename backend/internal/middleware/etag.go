@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"encoding/hex"
+	"hash/fnv"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ETag middleware adds a weak ETag computed from the body of successful GET
+// and HEAD responses and answers 304 Not Modified when the client's
+// If-None-Match already holds it. Handlers setting their own ETag are left
+// alone.
+func ETag() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.Next()
+			return
+		}
+
+		buffer(c, func(w *bufferedWriter) {
+			header := w.Header()
+			if w.status != http.StatusOK || header.Get("ETag") != "" {
+				w.send(w.body.Bytes())
+				return
+			}
+
+			etag := weakETag(w.body.Bytes())
+			header.Set("ETag", etag)
+
+			if etagMatches(c.GetHeader("If-None-Match"), etag) {
+				header.Del("Content-Type")
+				header.Del("Content-Length")
+				w.status = http.StatusNotModified
+				w.send(nil)
+				return
+			}
+			w.send(w.body.Bytes())
+		})
+	}
+}
+
+// weakETag hashes the body, weak because compressed and identity
+// representations share it
+func weakETag(body []byte) string {
+	h := fnv.New64a()
+	_, _ = h.Write(body)
+	return `W/"` + hex.EncodeToString(h.Sum(nil)) + `"`
+}
+
+// etagMatches applies the weak comparison of If-None-Match (RFC 9110 13.1.2)
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	want := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newETagRouter() *gin.Engine {
+	router := gin.New()
+	router.Use(ETag())
+	router.GET("/items", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"items": []int{1, 2, 3}})
+	})
+	router.GET("/custom", func(c *gin.Context) {
+		c.Header("ETag", `"v1"`)
+		c.String(http.StatusOK, "custom")
+	})
+	router.GET("/missing", func(c *gin.Context) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "missing"})
+	})
+	router.POST("/items", func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{"id": 4})
+	})
+	return router
+}
+
+func TestETagNotModified(t *testing.T) {
+	router := newETagRouter()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items", nil))
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || len(etag) < 4 || etag[:3] != `W/"` {
+		t.Fatalf("Expected 200 with a weak ETag, got %d %q", w.Code, etag)
+	}
+
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		status      int
+	}{
+		{"same etag", etag, http.StatusNotModified},
+		{"strong form of same etag", etag[2:], http.StatusNotModified},
+		{"in a list", `"other", ` + etag, http.StatusNotModified},
+		{"wildcard", "*", http.StatusNotModified},
+		{"stale etag", `W/"0000"`, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/items", nil)
+			req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Errorf("Expected %d, got %d", tt.status, w.Code)
+			}
+			if w.Header().Get("ETag") != etag {
+				t.Errorf("Expected ETag %q, got %q", etag, w.Header().Get("ETag"))
+			}
+			if tt.status == http.StatusNotModified && w.Body.Len() != 0 {
+				t.Errorf("Expected empty body on 304, got %q", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestETagSkips(t *testing.T) {
+	router := newETagRouter()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		etag   string
+	}{
+		{"handler etag", http.MethodGet, "/custom", `"v1"`},
+		{"error response", http.MethodGet, "/missing", ""},
+		{"non GET", http.MethodPost, "/items", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+			if got := w.Header().Get("ETag"); got != tt.etag {
+				t.Errorf("Expected ETag %q, got %q", tt.etag, got)
+			}
+		})
+	}
+}
+
+func TestETagWithCompression(t *testing.T) {
+	router := gin.New()
+	router.Use(Compress(CompressOptions{MinSize: 0, ContentTypes: []string{"text/"}}))
+	router.Use(ETag())
+	router.GET("/text", func(c *gin.Context) {
+		c.String(http.StatusOK, strings.Repeat("hello ", 200))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/text", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Header().Get("Content-Encoding") != "gzip" || w.Header().Get("ETag") == "" {
+		t.Fatalf("Expected a compressed response with an ETag, got %v", w.Header())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/text", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	req.Header.Set("If-None-Match", w.Header().Get("ETag"))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotModified || w.Header().Get("Content-Encoding") != "" {
+		t.Errorf("Expected a plain 304, got %d %v", w.Code, w.Header())
+	}
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/tracing"
+)
+
+// RequestIDHeader is the header used to carry the request ID
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDKey is the gin.Context key holding the request ID
+const RequestIDKey = "request_id"
+
+// RequestLogger middleware writes one structured log record per request.
+// Server errors are logged at error level, client errors at warn level and
+// everything else at info level.
+func RequestLogger(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		requestID := ensureRequestID(c)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("request_id", requestID),
+			slog.String("method", c.Request.Method),
+			slog.String("route", c.FullPath()),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Duration("latency", time.Since(start)),
+			slog.Int("bytes", max(c.Writer.Size(), 0)),
+			slog.String("client_ip", c.ClientIP()),
+			slog.String("user_agent", c.Request.UserAgent()),
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("errors", c.Errors.String()))
+		}
+
+		logger.LogAttrs(c.Request.Context(), level, "request", attrs...)
+	}
+}
+
+// ensureRequestID returns the ID of the request, assigning one on first use.
+// A valid X-Request-ID from the client is kept, otherwise a new ID is
+// generated. The ID is stored in the gin and request contexts and echoed in
+// the response.
+func ensureRequestID(c *gin.Context) string {
+	if id := c.GetString(RequestIDKey); id != "" {
+		return id
+	}
+
+	id := c.GetHeader(RequestIDHeader)
+	if !tracing.ValidRequestID(id) {
+		id = tracing.NewRequestID()
+	}
+	c.Set(RequestIDKey, id)
+	c.Header(RequestIDHeader, id)
+	c.Request = c.Request.WithContext(tracing.WithRequestID(c.Request.Context(), id))
+	return id
+}
@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func newLoggedRouter(buf *bytes.Buffer, level slog.Level) *gin.Engine {
+	logger := slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: level}))
+	router := gin.New()
+	router.Use(RequestLogger(logger))
+	router.GET("/users/:id", func(c *gin.Context) {
+		c.String(http.StatusOK, "hello")
+	})
+	router.GET("/boom", func(c *gin.Context) {
+		c.Status(http.StatusInternalServerError)
+	})
+	return router
+}
+
+func decodeRecord(t *testing.T, buf *bytes.Buffer) map[string]any {
+	t.Helper()
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Log output is not JSON: %v (%q)", err, buf.String())
+	}
+	return record
+}
+
+func TestRequestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	router := newLoggedRouter(&buf, slog.LevelInfo)
+
+	req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	req.Header.Set(RequestIDHeader, "abc-123")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if got := w.Header().Get(RequestIDHeader); got != "abc-123" {
+		t.Errorf("Expected request ID to be echoed, got %q", got)
+	}
+
+	record := decodeRecord(t, &buf)
+	expected := map[string]any{
+		"level":      "INFO",
+		"request_id": "abc-123",
+		"method":     "GET",
+		"route":      "/users/:id",
+		"path":       "/users/42",
+		"status":     float64(200),
+		"bytes":      float64(5),
+	}
+	for key, want := range expected {
+		if record[key] != want {
+			t.Errorf("Expected %s to be %v, got %v", key, want, record[key])
+		}
+	}
+	if _, ok := record["latency"]; !ok {
+		t.Error("Expected latency to be logged")
+	}
+	if _, ok := record["client_ip"]; !ok {
+		t.Error("Expected client_ip to be logged")
+	}
+}
+
+func TestRequestLoggerGeneratesID(t *testing.T) {
+	var buf bytes.Buffer
+	router := newLoggedRouter(&buf, slog.LevelInfo)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+
+	id := w.Header().Get(RequestIDHeader)
+	if len(id) != 32 {
+		t.Errorf("Expected generated 32 char request ID, got %q", id)
+	}
+	if record := decodeRecord(t, &buf); record["request_id"] != id {
+		t.Errorf("Expected logged request ID %q, got %v", id, record["request_id"])
+	}
+}
+
+func TestRequestLoggerLevels(t *testing.T) {
+	var buf bytes.Buffer
+	router := newLoggedRouter(&buf, slog.LevelWarn)
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	if buf.Len() != 0 {
+		t.Errorf("Expected successful request to be filtered at warn level, got %q", buf.String())
+	}
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/boom", nil))
+	if record := decodeRecord(t, &buf); record["level"] != "ERROR" {
+		t.Errorf("Expected server error to be logged at ERROR, got %v", record["level"])
+	}
+}
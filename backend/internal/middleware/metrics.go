@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/metrics"
+)
+
+// unmatchedRoute labels requests that did not match any route, so that
+// arbitrary paths cannot blow up the metric cardinality
+const unmatchedRoute = "unmatched"
+
+// Metrics middleware records the request count, latency and in-flight
+// requests. Requests are labeled with the route template, not the raw path.
+func Metrics(m *metrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		m.RequestsInFlight.Inc()
+		defer m.RequestsInFlight.Dec()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		status := strconv.Itoa(c.Writer.Status())
+
+		m.RequestsTotal.WithLabelValues(c.Request.Method, route, status).Inc()
+		m.RequestDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
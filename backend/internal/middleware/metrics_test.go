@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/metrics"
+)
+
+func TestMetrics(t *testing.T) {
+	m := metrics.New()
+	router := gin.New()
+	router.Use(Metrics(m))
+	router.GET("/users/:id", func(c *gin.Context) {
+		if got := testutil.ToFloat64(m.RequestsInFlight); got != 1 {
+			t.Errorf("Expected 1 request in flight, got %v", got)
+		}
+		c.Status(http.StatusOK)
+	})
+
+	for _, path := range []string{"/users/1", "/users/2", "/missing"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	if got := testutil.ToFloat64(m.RequestsTotal.WithLabelValues("GET", "/users/:id", "200")); got != 2 {
+		t.Errorf("Expected 2 requests for the route template, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.RequestsTotal.WithLabelValues("GET", unmatchedRoute, "404")); got != 1 {
+		t.Errorf("Expected 1 unmatched request, got %v", got)
+	}
+	if got := testutil.CollectAndCount(m.RequestDuration); got != 2 {
+		t.Errorf("Expected 2 latency series, got %d", got)
+	}
+	if got := testutil.ToFloat64(m.RequestsInFlight); got != 0 {
+		t.Errorf("Expected no requests in flight, got %v", got)
+	}
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/apierror"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/ratelimit"
+)
+
+// KeyFunc extracts the rate limiting key of a request
+type KeyFunc func(c *gin.Context) string
+
+// KeyByIP limits requests per client IP
+func KeyByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// KeyByUser limits requests per authenticated user, falling back to the
+// client IP when RequireAuth has not run
+func KeyByUser(c *gin.Context) string {
+	if user, ok := CurrentUser(c); ok {
+		return "user:" + strconv.FormatInt(user.ID, 10)
+	}
+	return KeyByIP(c)
+}
+
+// RateLimit middleware applies a token bucket per key. The scope separates
+// buckets of different route groups sharing a store. Every response carries
+// RateLimit-* headers and rejected requests get 429 with Retry-After.
+// Store failures let the request through so an outage never blocks the API.
+func RateLimit(store ratelimit.Store, scope string, limit ratelimit.Limit, key KeyFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		result, err := store.Allow(c.Request.Context(), scope+":"+key(c), limit)
+		if err != nil {
+			slog.WarnContext(c.Request.Context(), "rate limit store failed",
+				slog.String("scope", scope), slog.String("error", err.Error()))
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter)))
+
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(max(ceilSeconds(result.RetryAfter), 1)))
+			RespondError(c, apierror.New(http.StatusTooManyRequests, apierror.CodeRateLimited, "rate limit exceeded"))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/models"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/ratelimit"
+)
+
+type failingStore struct{}
+
+func (failingStore) Allow(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("store unavailable")
+}
+
+func TestRateLimit(t *testing.T) {
+	router := gin.New()
+	router.Use(RateLimit(ratelimit.NewMemoryStore(), "api", ratelimit.Limit{Rate: 1, Burst: 2}, KeyByIP))
+	router.GET("/ping", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	request := func(ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		req.RemoteAddr = ip + ":1234"
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request("10.0.0.1")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", w.Code)
+	}
+	if w.Header().Get("RateLimit-Limit") != "2" || w.Header().Get("RateLimit-Remaining") != "1" {
+		t.Errorf("Unexpected rate limit headers: %v", w.Header())
+	}
+
+	request("10.0.0.1")
+	w = request("10.0.0.1")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected 429, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") != "1" {
+		t.Errorf("Expected Retry-After 1, got %q", w.Header().Get("Retry-After"))
+	}
+
+	if w := request("10.0.0.2"); w.Code != http.StatusOK {
+		t.Errorf("Expected other clients to be unaffected, got %d", w.Code)
+	}
+}
+
+func TestRateLimitFailsOpen(t *testing.T) {
+	router := gin.New()
+	router.Use(RateLimit(failingStore{}, "api", ratelimit.Limit{Rate: 1, Burst: 1}, KeyByIP))
+	router.GET("/ping", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ping", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected request to pass when the store fails, got %d", w.Code)
+	}
+}
+
+func TestKeyByUser(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	c.Request.RemoteAddr = "10.0.0.1:1234"
+
+	if got := KeyByUser(c); got != "ip:10.0.0.1" {
+		t.Errorf("Expected IP fallback, got %q", got)
+	}
+
+	c.Set(UserKey, &models.User{ID: 42})
+	if got := KeyByUser(c); got != "user:42" {
+		t.Errorf("Expected user key, got %q", got)
+	}
+}
+
+func TestRateLimitIgnoresForwardedForFromUntrustedPeers(t *testing.T) {
+	router := gin.New()
+	if err := router.SetTrustedProxies(nil); err != nil {
+		t.Fatalf("SetTrustedProxies() failed: %v", err)
+	}
+	router.Use(RateLimit(ratelimit.NewMemoryStore(), "api", ratelimit.Limit{Rate: 1, Burst: 1}, KeyByIP))
+	router.GET("/ping", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	request := func(forwardedFor string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := request("203.0.113.1"); w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", w.Code)
+	}
+	if w := request("203.0.113.2"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected a spoofed X-Forwarded-For to hit the same bucket, got %d", w.Code)
+	}
+}
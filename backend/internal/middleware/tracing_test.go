@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/tracing"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const parentTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+func newTracedRouter(t *testing.T) (*gin.Engine, *tracetest.InMemoryExporter) {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { _ = provider.Shutdown(t.Context()) })
+
+	router := gin.New()
+	router.Use(Tracing(provider.Tracer("test")))
+	router.GET("/users/:id", func(c *gin.Context) {
+		ctx := c.Request.Context()
+		c.JSON(http.StatusOK, gin.H{
+			"request_id": tracing.RequestIDFromContext(ctx),
+			"trace_id":   trace.SpanContextFromContext(ctx).TraceID().String(),
+		})
+	})
+	return router, exporter
+}
+
+func TestTracingContinuesIncomingTrace(t *testing.T) {
+	router, exporter := newTracedRouter(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/users/7", nil)
+	req.Header.Set("traceparent", "00-"+parentTraceID+"-00f067aa0ba902b7-01")
+	req.Header.Set(RequestIDHeader, "client-id-1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	body := w.Body.String()
+	if !strings.Contains(body, `"request_id":"client-id-1"`) || !strings.Contains(body, parentTraceID) {
+		t.Errorf("Expected request and trace IDs in the request context, got %s", body)
+	}
+	if got := w.Header().Get(RequestIDHeader); got != "client-id-1" {
+		t.Errorf("Expected request ID to be echoed, got %q", got)
+	}
+	if got := w.Header().Get("traceparent"); !strings.HasPrefix(got, "00-"+parentTraceID+"-") {
+		t.Errorf("Expected traceparent of the same trace, got %q", got)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+	if spans[0].Name != "GET /users/:id" || spans[0].Parent.SpanID().String() != "00f067aa0ba902b7" {
+		t.Errorf("Unexpected span %s with parent %s", spans[0].Name, spans[0].Parent.SpanID())
+	}
+}
+
+func TestTracingStartsNewTrace(t *testing.T) {
+	router, exporter := newTracedRouter(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/users/7", nil)
+	req.Header.Set(RequestIDHeader, "bad id\nwith newline")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if got := w.Header().Get(RequestIDHeader); len(got) != 32 {
+		t.Errorf("Expected invalid request ID to be replaced, got %q", got)
+	}
+	traceparent := w.Header().Get("traceparent")
+	spans := exporter.GetSpans()
+	if len(spans) != 1 || !strings.Contains(traceparent, spans[0].SpanContext.TraceID().String()) {
+		t.Errorf("Expected traceparent %q to carry the new trace", traceparent)
+	}
+}
@@ -0,0 +1,194 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"time"
+
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+)
+
+// Migration states reported by Status
+const (
+	StatePending = "pending"
+	StateApplied = "applied"
+)
+
+// ErrNothingToRollback is returned by Down and Redo when no migration is applied
+var ErrNothingToRollback = errors.New("no applied migrations to roll back")
+
+// Result describes a single applied or rolled back migration
+type Result struct {
+	Version   int64
+	Name      string
+	Direction string
+	Duration  time.Duration
+}
+
+// Status describes the state of a single migration file
+type Status struct {
+	Version   int64
+	Name      string
+	State     string
+	AppliedAt time.Time
+}
+
+// Migrator applies versioned SQL migrations and records them in the
+// goose_db_version table. Every migration file runs in its own transaction
+// unless it is annotated with "-- +goose NO TRANSACTION".
+type Migrator struct {
+	provider *goose.Provider
+}
+
+// New creates a migrator for the given database and migration files.
+// On Postgres an advisory lock guards against concurrent runs.
+func New(db *sql.DB, dialect goose.Dialect, fsys fs.FS) (*Migrator, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database connection cannot be nil")
+	}
+
+	var opts []goose.ProviderOption
+	if dialect == goose.DialectPostgres {
+		locker, err := lock.NewPostgresSessionLocker()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create session locker: %w", err)
+		}
+		opts = append(opts, goose.WithSessionLocker(locker))
+	}
+
+	provider, err := goose.NewProvider(dialect, db, fsys, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+	return &Migrator{provider: provider}, nil
+}
+
+// Up applies all pending migrations
+func (m *Migrator) Up(ctx context.Context) ([]Result, error) {
+	res, err := m.provider.Up(ctx)
+	return toResults(res), err
+}
+
+// UpTo applies pending migrations up to and including the given version
+func (m *Migrator) UpTo(ctx context.Context, version int64) ([]Result, error) {
+	if err := m.checkVersion(version); err != nil {
+		return nil, err
+	}
+	res, err := m.provider.UpTo(ctx, version)
+	return toResults(res), err
+}
+
+// Down rolls back the most recently applied migration
+func (m *Migrator) Down(ctx context.Context) (Result, error) {
+	res, err := m.provider.Down(ctx)
+	if errors.Is(err, goose.ErrNoNextVersion) {
+		return Result{}, ErrNothingToRollback
+	}
+	if err != nil {
+		return Result{}, err
+	}
+	return toResult(res), nil
+}
+
+// DownTo rolls back applied migrations down to, but not including, the given version.
+// Version 0 rolls back everything.
+func (m *Migrator) DownTo(ctx context.Context, version int64) ([]Result, error) {
+	if version != 0 {
+		if err := m.checkVersion(version); err != nil {
+			return nil, err
+		}
+	}
+	res, err := m.provider.DownTo(ctx, version)
+	return toResults(res), err
+}
+
+// Redo rolls back the most recently applied migration and applies it again
+func (m *Migrator) Redo(ctx context.Context) ([]Result, error) {
+	current, err := m.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if current == 0 {
+		return nil, ErrNothingToRollback
+	}
+
+	down, err := m.provider.ApplyVersion(ctx, current, false)
+	if err != nil {
+		return nil, err
+	}
+	up, err := m.provider.ApplyVersion(ctx, current, true)
+	if err != nil {
+		return []Result{toResult(down)}, err
+	}
+	return []Result{toResult(down), toResult(up)}, nil
+}
+
+// Status returns the state of every known migration ordered by version
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	statuses, err := m.provider.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Status, 0, len(statuses))
+	for _, s := range statuses {
+		result = append(result, Status{
+			Version:   s.Source.Version,
+			Name:      path.Base(s.Source.Path),
+			State:     string(s.State),
+			AppliedAt: s.AppliedAt,
+		})
+	}
+	return result, nil
+}
+
+// Version returns the current schema version of the database, 0 if nothing is applied
+func (m *Migrator) Version(ctx context.Context) (int64, error) {
+	return m.provider.GetDBVersion(ctx)
+}
+
+// Latest returns the highest version among the known migration files
+func (m *Migrator) Latest() int64 {
+	var latest int64
+	for _, s := range m.provider.ListSources() {
+		if s.Version > latest {
+			latest = s.Version
+		}
+	}
+	return latest
+}
+
+// checkVersion returns an error if no migration file has the given version
+func (m *Migrator) checkVersion(version int64) error {
+	for _, s := range m.provider.ListSources() {
+		if s.Version == version {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown migration version %d", version)
+}
+
+func toResults(in []*goose.MigrationResult) []Result {
+	out := make([]Result, 0, len(in))
+	for _, r := range in {
+		out = append(out, toResult(r))
+	}
+	return out
+}
+
+func toResult(r *goose.MigrationResult) Result {
+	if r == nil || r.Source == nil {
+		return Result{}
+	}
+	return Result{
+		Version:   r.Source.Version,
+		Name:      path.Base(r.Source.Path),
+		Direction: r.Direction,
+		Duration:  r.Duration,
+	}
+}
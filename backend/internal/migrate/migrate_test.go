@@ -0,0 +1,226 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/pressly/goose/v3"
+)
+
+func testMigrations() fstest.MapFS {
+	return fstest.MapFS{
+		"00001_create_users.sql": {Data: []byte(`
+-- +goose Up
+CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT NOT NULL);
+-- +goose Down
+DROP TABLE users;
+`)},
+		"00002_create_posts.sql": {Data: []byte(`
+-- +goose Up
+CREATE TABLE posts (id INTEGER PRIMARY KEY, user_id INTEGER NOT NULL);
+-- +goose Down
+DROP TABLE posts;
+`)},
+		"00003_add_title.sql": {Data: []byte(`
+-- +goose Up
+ALTER TABLE posts ADD COLUMN title TEXT;
+-- +goose Down
+ALTER TABLE posts DROP COLUMN title;
+`)},
+	}
+}
+
+func newTestMigrator(t *testing.T, fsys fstest.MapFS) (*Migrator, *sql.DB) {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	m, err := New(db, goose.DialectSQLite3, fsys)
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	return m, db
+}
+
+func tableExists(t *testing.T, db *sql.DB, name string) bool {
+	t.Helper()
+	var count int
+	err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", name).Scan(&count)
+	if err != nil {
+		t.Fatalf("Failed to query schema: %v", err)
+	}
+	return count == 1
+}
+
+func TestNewRequiresDB(t *testing.T) {
+	if _, err := New(nil, goose.DialectSQLite3, testMigrations()); err == nil {
+		t.Error("Expected error for nil database")
+	}
+}
+
+func TestUpAndVersion(t *testing.T) {
+	ctx := context.Background()
+	m, db := newTestMigrator(t, testMigrations())
+
+	if m.Latest() != 3 {
+		t.Errorf("Expected latest version 3, got %d", m.Latest())
+	}
+
+	results, err := m.Up(ctx)
+	if err != nil {
+		t.Fatalf("Up() failed: %v", err)
+	}
+	if len(results) != 3 {
+		t.Errorf("Expected 3 applied migrations, got %d", len(results))
+	}
+	if results[0].Name != "00001_create_users.sql" || results[0].Direction != "up" {
+		t.Errorf("Unexpected first result: %+v", results[0])
+	}
+
+	version, err := m.Version(ctx)
+	if err != nil {
+		t.Fatalf("Version() failed: %v", err)
+	}
+	if version != 3 {
+		t.Errorf("Expected version 3, got %d", version)
+	}
+	if !tableExists(t, db, "posts") {
+		t.Error("posts table should exist after Up()")
+	}
+
+	// Running again is a no-op
+	results, err = m.Up(ctx)
+	if err != nil {
+		t.Fatalf("Second Up() failed: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Expected no migrations on second run, got %d", len(results))
+	}
+}
+
+func TestUpToAndDownTo(t *testing.T) {
+	ctx := context.Background()
+	m, db := newTestMigrator(t, testMigrations())
+
+	if _, err := m.UpTo(ctx, 2); err != nil {
+		t.Fatalf("UpTo(2) failed: %v", err)
+	}
+	if version, _ := m.Version(ctx); version != 2 {
+		t.Errorf("Expected version 2, got %d", version)
+	}
+
+	if _, err := m.UpTo(ctx, 42); err == nil {
+		t.Error("Expected error for unknown version")
+	}
+
+	results, err := m.DownTo(ctx, 1)
+	if err != nil {
+		t.Fatalf("DownTo(1) failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Version != 2 {
+		t.Errorf("Expected only version 2 rolled back, got %+v", results)
+	}
+	if tableExists(t, db, "posts") {
+		t.Error("posts table should be dropped after DownTo(1)")
+	}
+
+	if _, err := m.DownTo(ctx, 0); err != nil {
+		t.Fatalf("DownTo(0) failed: %v", err)
+	}
+	if tableExists(t, db, "users") {
+		t.Error("users table should be dropped after DownTo(0)")
+	}
+}
+
+func TestDownAndRedo(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newTestMigrator(t, testMigrations())
+
+	if _, err := m.Down(ctx); !errors.Is(err, ErrNothingToRollback) {
+		t.Errorf("Expected ErrNothingToRollback, got %v", err)
+	}
+	if _, err := m.Redo(ctx); !errors.Is(err, ErrNothingToRollback) {
+		t.Errorf("Expected ErrNothingToRollback, got %v", err)
+	}
+
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatalf("Up() failed: %v", err)
+	}
+
+	results, err := m.Redo(ctx)
+	if err != nil {
+		t.Fatalf("Redo() failed: %v", err)
+	}
+	if len(results) != 2 || results[0].Direction != "down" || results[1].Direction != "up" {
+		t.Errorf("Unexpected redo results: %+v", results)
+	}
+	if version, _ := m.Version(ctx); version != 3 {
+		t.Errorf("Expected version 3 after redo, got %d", version)
+	}
+
+	result, err := m.Down(ctx)
+	if err != nil {
+		t.Fatalf("Down() failed: %v", err)
+	}
+	if result.Version != 3 {
+		t.Errorf("Expected version 3 rolled back, got %d", result.Version)
+	}
+	if version, _ := m.Version(ctx); version != 2 {
+		t.Errorf("Expected version 2 after down, got %d", version)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newTestMigrator(t, testMigrations())
+
+	if _, err := m.UpTo(ctx, 1); err != nil {
+		t.Fatalf("UpTo(1) failed: %v", err)
+	}
+
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status() failed: %v", err)
+	}
+	if len(statuses) != 3 {
+		t.Fatalf("Expected 3 statuses, got %d", len(statuses))
+	}
+	if statuses[0].State != StateApplied || statuses[0].AppliedAt.IsZero() {
+		t.Errorf("Expected first migration applied, got %+v", statuses[0])
+	}
+	if statuses[1].State != StatePending || statuses[2].State != StatePending {
+		t.Errorf("Expected remaining migrations pending, got %+v", statuses[1:])
+	}
+}
+
+func TestFailedMigrationIsRolledBack(t *testing.T) {
+	ctx := context.Background()
+	fsys := testMigrations()
+	fsys["00004_broken.sql"] = &fstest.MapFile{Data: []byte(`
+-- +goose Up
+CREATE TABLE comments (id INTEGER PRIMARY KEY);
+INSERT INTO missing_table VALUES (1);
+-- +goose Down
+DROP TABLE comments;
+`)}
+	m, db := newTestMigrator(t, fsys)
+
+	if _, err := m.Up(ctx); err == nil {
+		t.Fatal("Expected Up() to fail on broken migration")
+	}
+	if version, _ := m.Version(ctx); version != 3 {
+		t.Errorf("Expected version 3 after failed migration, got %d", version)
+	}
+	if tableExists(t, db, "comments") {
+		t.Error("Failed migration should be rolled back entirely")
+	}
+}
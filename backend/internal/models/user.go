@@ -0,0 +1,130 @@
+package models
+
+import (
+	"errors"
+	"net/mail"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Validation errors for user input
+var (
+	ErrInvalidEmail = errors.New("invalid email format")
+	ErrInvalidName  = errors.New("name must be between 2 and 100 characters")
+	ErrWeakPassword = errors.New("password must be at least 8 characters and contain a letter and a number")
+	ErrLongPassword = errors.New("password must be at most 72 bytes")
+)
+
+// User represents a registered user
+type User struct {
+	ID           int64      `json:"id"`
+	Email        string     `json:"email"`
+	Name         string     `json:"name"`
+	PasswordHash string     `json:"-"` // Never serialize the password hash
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	DisabledAt   *time.Time `json:"disabled_at,omitempty"`
+}
+
+// Disabled reports whether an administrator disabled the account
+func (u *User) Disabled() bool {
+	return u.DisabledAt != nil
+}
+
+// RegisterRequest represents the payload for creating an account
+type RegisterRequest struct {
+	Email    string `json:"email"`
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+// LoginRequest represents the payload for logging in
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// RefreshRequest carries a refresh token for the refresh and logout endpoints
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// TokenPair is returned after a successful login or refresh
+type TokenPair struct {
+	AccessToken      string    `json:"access_token"`
+	RefreshToken     string    `json:"refresh_token"`
+	TokenType        string    `json:"token_type"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+// AuthResponse is returned by the register and login endpoints
+type AuthResponse struct {
+	User   *User     `json:"user"`
+	Tokens TokenPair `json:"tokens"`
+}
+
+// Normalize trims whitespace and lowercases the email
+func (r *RegisterRequest) Normalize() {
+	r.Email = NormalizeEmail(r.Email)
+	r.Name = strings.TrimSpace(r.Name)
+}
+
+// Validate checks the registration payload
+func (r *RegisterRequest) Validate() error {
+	if err := ValidateEmail(r.Email); err != nil {
+		return err
+	}
+	if n := len([]rune(r.Name)); n < 2 || n > 100 {
+		return ErrInvalidName
+	}
+	return ValidatePassword(r.Password)
+}
+
+// NormalizeEmail trims whitespace and lowercases an email address
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// ValidateEmail checks that email is a bare address like user@example.com
+func ValidateEmail(email string) error {
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email || !strings.Contains(email[strings.LastIndex(email, "@"):], ".") {
+		return ErrInvalidEmail
+	}
+	return nil
+}
+
+// ValidatePassword checks that a password has at least 8 characters, a letter and a digit
+func ValidatePassword(password string) error {
+	if len(password) > 72 {
+		return ErrLongPassword
+	}
+	if len(password) < 8 {
+		return ErrWeakPassword
+	}
+	var hasLetter, hasDigit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+	if !hasLetter || !hasDigit {
+		return ErrWeakPassword
+	}
+	return nil
+}
+
+// RefreshToken is a stored refresh token, only its SHA-256 hash is persisted
+type RefreshToken struct {
+	ID        int64
+	UserID    int64
+	TokenHash string
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
@@ -0,0 +1,37 @@
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"html/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed viewer.html
+var viewerHTML string
+
+var viewerTemplate = template.Must(template.New("viewer").Parse(viewerHTML))
+
+// Handler serves the document as JSON. The document is encoded once, it
+// does not change while the server runs.
+func Handler(doc *Document) (gin.HandlerFunc, error) {
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+	}, nil
+}
+
+// ViewerHandler serves a self-contained HTML page rendering the document
+// found at specURL
+func ViewerHandler(title, specURL string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		c.Status(http.StatusOK)
+		_ = viewerTemplate.Execute(c.Writer, struct{ Title, SpecURL string }{title, specURL})
+	}
+}
@@ -0,0 +1,261 @@
+// Package openapi builds an OpenAPI 3 document from the routes registered on
+// a Gin engine and the Go types of their request and response bodies
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Version is the OpenAPI version of the generated documents
+const Version = "3.0.3"
+
+// bearerScheme names the security scheme of routes with Auth set
+const bearerScheme = "bearerAuth"
+
+// Document is an OpenAPI 3 document
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Servers    []Server             `json:"servers,omitempty"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+// Info describes the API
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// Server is a base URL the API is served from
+type Server struct {
+	URL string `json:"url"`
+}
+
+// PathItem holds the operations of one path keyed by lowercase method
+type PathItem map[string]*Operation
+
+// Operation documents one method of a path
+type Operation struct {
+	OperationID string                `json:"operationId,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+// Parameter documents a path or query parameter
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+// RequestBody documents a JSON request body
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response documents one response status
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType holds the schema of a body
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the named schemas and security schemes
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas,omitempty"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme documents how requests authenticate
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+// Route documents one registered route. Request and the Responses values are
+// values of the body types, e.g. models.LoginRequest{}; a nil response means
+// the status has no body.
+type Route struct {
+	Summary     string
+	Description string
+	Tags        []string
+	Auth        bool
+	Query       []string
+	Request     any
+	Responses   map[int]any
+}
+
+// Routes maps "METHOD /path" as registered on the engine to its documentation
+type Routes map[string]Route
+
+// Options configures Build
+type Options struct {
+	Info    Info
+	Servers []Server
+	// Ignore lists "METHOD /path" keys of routes left out of the document
+	Ignore []string
+	// Error is the body of every error response, added as the default response
+	Error any
+}
+
+// Key returns the Routes key of a route
+func Key(method, path string) string {
+	return method + " " + path
+}
+
+// paramPattern matches gin path parameters and wildcards
+var paramPattern = regexp.MustCompile(`[:*]([A-Za-z0-9_]+)`)
+
+// Build documents every route of the engine. Routes without documentation,
+// documentation of unregistered routes and routes without any response are
+// all reported in the returned error.
+func Build(routes gin.RoutesInfo, docs Routes, opts Options) (*Document, error) {
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    opts.Info,
+		Servers: opts.Servers,
+		Paths:   map[string]*PathItem{},
+		Components: Components{
+			Schemas: map[string]*Schema{},
+		},
+	}
+	gen := newGenerator(doc.Components.Schemas)
+
+	ignored := map[string]bool{}
+	for _, key := range opts.Ignore {
+		ignored[key] = true
+	}
+
+	var errorSchema *Schema
+	if opts.Error != nil {
+		errorSchema = gen.schemaOf(opts.Error)
+	}
+
+	var errs []error
+	seen := map[string]bool{}
+	for _, route := range routes {
+		key := Key(route.Method, route.Path)
+		if ignored[key] {
+			continue
+		}
+		seen[key] = true
+
+		spec, ok := docs[key]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: route is not documented", key))
+			continue
+		}
+		if len(spec.Responses) == 0 {
+			errs = append(errs, fmt.Errorf("%s: no responses documented", key))
+			continue
+		}
+
+		path, params := convertPath(route.Path)
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = &PathItem{}
+			doc.Paths[path] = item
+		}
+
+		op := &Operation{
+			OperationID: operationID(route.Method, route.Path),
+			Summary:     spec.Summary,
+			Description: spec.Description,
+			Tags:        spec.Tags,
+			Responses:   map[string]*Response{},
+		}
+		for _, name := range params {
+			op.Parameters = append(op.Parameters, Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+		}
+		for _, name := range spec.Query {
+			op.Parameters = append(op.Parameters, Parameter{Name: name, In: "query", Schema: &Schema{Type: "string"}})
+		}
+		if spec.Request != nil {
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]MediaType{"application/json": {Schema: gen.schemaOf(spec.Request)}},
+			}
+		}
+		for status, body := range spec.Responses {
+			resp := &Response{Description: http.StatusText(status)}
+			if body != nil {
+				resp.Content = map[string]MediaType{"application/json": {Schema: gen.schemaOf(body)}}
+			}
+			op.Responses[strconv.Itoa(status)] = resp
+		}
+		if errorSchema != nil {
+			op.Responses["default"] = &Response{
+				Description: "Error",
+				Content:     map[string]MediaType{"application/json": {Schema: errorSchema}},
+			}
+		}
+		if spec.Auth {
+			op.Security = []map[string][]string{{bearerScheme: {}}}
+			doc.Components.SecuritySchemes = map[string]SecurityScheme{
+				bearerScheme: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			}
+		}
+
+		(*item)[strings.ToLower(route.Method)] = op
+	}
+
+	stale := make([]string, 0)
+	for key := range docs {
+		if !seen[key] {
+			stale = append(stale, key)
+		}
+	}
+	sort.Strings(stale)
+	for _, key := range stale {
+		errs = append(errs, fmt.Errorf("%s: documented route is not registered", key))
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return doc, nil
+}
+
+// convertPath turns /users/:id into /users/{id} and returns the parameter names
+func convertPath(path string) (string, []string) {
+	var params []string
+	converted := paramPattern.ReplaceAllStringFunc(path, func(match string) string {
+		params = append(params, match[1:])
+		return "{" + match[1:] + "}"
+	})
+	return converted, params
+}
+
+// operationID derives a stable identifier such as postApiV1AuthLogin
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool {
+		return r == '/' || r == ':' || r == '*' || r == '-' || r == '_' || r == '.'
+	}) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
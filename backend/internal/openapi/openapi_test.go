@@ -0,0 +1,147 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type testItem struct {
+	ID        int64      `json:"id"`
+	Title     string     `json:"title"`
+	Note      *string    `json:"note,omitempty"`
+	Tags      []string   `json:"tags"`
+	DueAt     *time.Time `json:"due_at"`
+	Parent    *testItem  `json:"parent,omitempty"`
+	internal  string
+	Hidden    string `json:"-"`
+	CreatedAt time.Time
+}
+
+type testError struct {
+	Message string `json:"message"`
+}
+
+func testRoutes() gin.RoutesInfo {
+	return gin.RoutesInfo{
+		{Method: http.MethodGet, Path: "/items/:id"},
+		{Method: http.MethodPost, Path: "/items"},
+		{Method: http.MethodGet, Path: "/metrics"},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	docs := Routes{
+		"GET /items/:id": {Summary: "Get item", Auth: true, Responses: map[int]any{http.StatusOK: testItem{}}},
+		"POST /items":    {Request: testItem{}, Responses: map[int]any{http.StatusCreated: &testItem{}}},
+	}
+
+	doc, err := Build(testRoutes(), docs, Options{
+		Info:   Info{Title: "Test", Version: "1"},
+		Ignore: []string{"GET /metrics"},
+		Error:  testError{},
+	})
+	if err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	get := (*doc.Paths["/items/{id}"])["get"]
+	if get == nil {
+		t.Fatalf("Expected converted path /items/{id}, got %v", doc.Paths)
+	}
+	if len(get.Parameters) != 1 || get.Parameters[0].Name != "id" || get.Parameters[0].In != "path" {
+		t.Errorf("Expected id path parameter, got %+v", get.Parameters)
+	}
+	if get.OperationID != "getItemsId" || len(get.Security) != 1 {
+		t.Errorf("Unexpected operation %+v", get)
+	}
+	if get.Responses["200"].Content["application/json"].Schema.Ref != "#/components/schemas/testItem" {
+		t.Errorf("Expected response to reference the component schema")
+	}
+	if get.Responses["default"] == nil {
+		t.Error("Expected default error response")
+	}
+	if _, ok := doc.Paths["/metrics"]; ok {
+		t.Error("Ignored route should not be documented")
+	}
+
+	item := doc.Components.Schemas["testItem"]
+	if item == nil {
+		t.Fatal("Expected testItem component")
+	}
+	want := []string{"id", "title", "tags", "due_at", "CreatedAt"}
+	if strings.Join(item.Required, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected required %v, got %v", want, item.Required)
+	}
+	if _, ok := item.Properties["Hidden"]; ok {
+		t.Error("Fields tagged json:\"-\" should be skipped")
+	}
+	if len(item.Properties) != 7 {
+		t.Errorf("Expected 7 properties, got %d", len(item.Properties))
+	}
+	if due := item.Properties["due_at"]; due.Format != "date-time" || !due.Nullable {
+		t.Errorf("Unexpected due_at schema %+v", due)
+	}
+	if item.Properties["parent"].Ref != "#/components/schemas/testItem" {
+		t.Error("Expected recursive reference for parent")
+	}
+	if item.Properties["tags"].Items.Type != "string" {
+		t.Error("Expected tags to be an array of strings")
+	}
+}
+
+func TestBuildReportsMissingDocs(t *testing.T) {
+	docs := Routes{
+		"POST /items":   {Request: testItem{}},
+		"DELETE /items": {Responses: map[int]any{http.StatusNoContent: nil}},
+	}
+
+	_, err := Build(testRoutes(), docs, Options{})
+	if err == nil {
+		t.Fatal("Expected Build() to fail")
+	}
+	for _, want := range []string{
+		"GET /items/:id: route is not documented",
+		"GET /metrics: route is not documented",
+		"POST /items: no responses documented",
+		"DELETE /items: documented route is not registered",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to contain %q, got:\n%v", want, err)
+		}
+	}
+}
+
+func TestHandlers(t *testing.T) {
+	doc := &Document{OpenAPI: Version, Info: Info{Title: "Test", Version: "1"}, Paths: map[string]*PathItem{}}
+	handler, err := Handler(doc)
+	if err != nil {
+		t.Fatalf("Handler() failed: %v", err)
+	}
+
+	router := gin.New()
+	router.GET("/openapi.json", handler)
+	router.GET("/docs", ViewerHandler("Test <API>", "/openapi.json"))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	var got Document
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil || got.OpenAPI != Version {
+		t.Errorf("Expected the JSON document, got %q", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	body := w.Body.String()
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") {
+		t.Errorf("Expected HTML viewer, got %q", w.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(body, "Test &lt;API&gt;") || !strings.Contains(body, `"/openapi.json"`) {
+		t.Error("Expected escaped title and spec URL in the viewer")
+	}
+}
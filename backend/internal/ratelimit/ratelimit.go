@@ -0,0 +1,70 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit describes a token bucket: Burst tokens, refilled at Rate tokens per second
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Result is the outcome of taking a token from a bucket
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter is how long to wait until the next token is available
+	RetryAfter time.Duration
+	// ResetAfter is how long until the bucket is full again
+	ResetAfter time.Duration
+}
+
+// Store keeps token buckets. Implementations must be safe for concurrent use,
+// MemoryStore serves a single instance and a shared store can back several.
+type Store interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// ParseLimit parses limits written as "<count>/<unit>:<burst>", e.g. "20/s:40"
+// or "5/m:10". Units are s, m and h. The burst defaults to the count.
+func ParseLimit(spec string) (Limit, error) {
+	spec = strings.TrimSpace(spec)
+	ratePart, burstPart, hasBurst := strings.Cut(spec, ":")
+	countPart, unit, ok := strings.Cut(ratePart, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid rate limit %q, expected <count>/<unit>[:<burst>]", spec)
+	}
+
+	count, err := strconv.Atoi(countPart)
+	if err != nil || count <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit count in %q", spec)
+	}
+
+	var per time.Duration
+	switch unit {
+	case "s":
+		per = time.Second
+	case "m":
+		per = time.Minute
+	case "h":
+		per = time.Hour
+	default:
+		return Limit{}, fmt.Errorf("invalid rate limit unit in %q, use s, m or h", spec)
+	}
+
+	burst := count
+	if hasBurst {
+		burst, err = strconv.Atoi(burstPart)
+		if err != nil || burst <= 0 {
+			return Limit{}, fmt.Errorf("invalid rate limit burst in %q", spec)
+		}
+	}
+
+	return Limit{Rate: float64(count) / per.Seconds(), Burst: burst}, nil
+}
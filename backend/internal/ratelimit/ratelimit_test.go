@@ -0,0 +1,102 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    Limit
+		wantErr bool
+	}{
+		{"20/s:40", Limit{Rate: 20, Burst: 40}, false},
+		{"60/m", Limit{Rate: 1, Burst: 60}, false},
+		{"3600/h:10", Limit{Rate: 1, Burst: 10}, false},
+		{"20", Limit{}, true},
+		{"0/s", Limit{}, true},
+		{"5/d", Limit{}, true},
+		{"5/s:0", Limit{}, true},
+		{"x/s", Limit{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseLimit(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error for %q", tt.spec)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseLimit(%q) failed: %v", tt.spec, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseLimit(%q) = %+v, want %+v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryStoreTokenBucket(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1000, 0)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	limit := Limit{Rate: 1, Burst: 3}
+
+	for i := 0; i < 3; i++ {
+		res, err := store.Allow(ctx, "k", limit)
+		if err != nil {
+			t.Fatalf("Allow() failed: %v", err)
+		}
+		if !res.Allowed || res.Remaining != 2-i {
+			t.Errorf("Request %d: unexpected result %+v", i, res)
+		}
+	}
+
+	res, _ := store.Allow(ctx, "k", limit)
+	if res.Allowed {
+		t.Fatal("Expected fourth request to be rejected")
+	}
+	if res.RetryAfter != time.Second || res.ResetAfter != 3*time.Second {
+		t.Errorf("Unexpected retry/reset: %+v", res)
+	}
+
+	// Other keys have their own bucket
+	if res, _ := store.Allow(ctx, "other", limit); !res.Allowed {
+		t.Error("Expected a separate bucket per key")
+	}
+
+	now = now.Add(1500 * time.Millisecond)
+	if res, _ := store.Allow(ctx, "k", limit); !res.Allowed {
+		t.Error("Expected a token to be refilled after 1.5s")
+	}
+	if res, _ := store.Allow(ctx, "k", limit); res.Allowed {
+		t.Error("Expected only one token to be refilled")
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1000, 0)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	store.lastSweep = now
+	limit := Limit{Rate: 1, Burst: 1}
+
+	store.Allow(ctx, "a", limit)
+	store.Allow(ctx, "b", limit)
+	if store.Len() != 2 {
+		t.Fatalf("Expected 2 buckets, got %d", store.Len())
+	}
+
+	now = now.Add(2 * time.Minute)
+	store.Allow(ctx, "c", limit)
+	if store.Len() != 1 {
+		t.Errorf("Expected idle buckets to be swept, got %d", store.Len())
+	}
+}
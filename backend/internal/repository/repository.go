@@ -0,0 +1,18 @@
+package repository
+
+import "database/sql"
+
+// Repositories bundles the repositories backed by one database, main builds it
+// once from the pool opened by the database package and hands it to services and handlers
+type Repositories struct {
+	Users         *UserRepository
+	RefreshTokens *RefreshTokenRepository
+}
+
+// New creates the repositories for db
+func New(db *sql.DB) *Repositories {
+	return &Repositories{
+		Users:         NewUserRepository(db),
+		RefreshTokens: NewRefreshTokenRepository(db),
+	}
+}
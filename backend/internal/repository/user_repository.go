@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/models"
+)
+
+// UserRepository handles database operations for users
+type UserRepository struct {
+	db *sql.DB
+}
+
+// NewUserRepository creates a new UserRepository
+func NewUserRepository(db *sql.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+const userColumns = `id, email, name, password_hash, created_at, updated_at, disabled_at`
+
+// Create inserts a user and fills in the generated ID and timestamps
+func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO users (email, name, password_hash)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at, updated_at`,
+		user.Email, user.Name, user.PasswordHash,
+	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+	if isUniqueViolation(err) {
+		return ErrEmailTaken
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+	return nil
+}
+
+// GetByID returns the user with the given ID or ErrNotFound
+func (r *UserRepository) GetByID(ctx context.Context, id int64) (*models.User, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE id = $1`, id)
+	return scanUser(row)
+}
+
+// GetByEmail returns the user with the given email or ErrNotFound
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE email = $1`, email)
+	return scanUser(row)
+}
+
+// UpdatePassword replaces the password hash of a user
+func (r *UserRepository) UpdatePassword(ctx context.Context, id int64, passwordHash string) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE users SET password_hash = $2, updated_at = NOW()
+		WHERE id = $1`,
+		id, passwordHash,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+	return requireAffected(result)
+}
+
+// SetDisabled disables or re-enables a user
+func (r *UserRepository) SetDisabled(ctx context.Context, id int64, disabled bool) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE users
+		SET disabled_at = CASE WHEN $2 THEN COALESCE(disabled_at, NOW()) END, updated_at = NOW()
+		WHERE id = $1`,
+		id, disabled,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+	return requireAffected(result)
+}
+
+func scanUser(row *sql.Row) (*models.User, error) {
+	var user models.User
+	err := row.Scan(&user.ID, &user.Email, &user.Name, &user.PasswordHash, &user.CreatedAt, &user.UpdatedAt, &user.DisabledAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan user: %w", err)
+	}
+	return &user, nil
+}
@@ -0,0 +1,353 @@
+// Package seed loads fixture files into the database. Seeding is idempotent:
+// records that already exist are skipped, so a fixture can be applied again
+// after it was edited or on every start of a development environment.
+//
+// Users are identified by email, categories and posts by slug. Posts refer to
+// their author by email and to their category by slug, the referenced records
+// may come from the same fixtures or already exist in the database.
+package seed
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/auth"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// Fixtures is the content of one or more fixture files
+type Fixtures struct {
+	Users      []User     `yaml:"users" json:"users"`
+	Categories []Category `yaml:"categories" json:"categories"`
+	Posts      []Post     `yaml:"posts" json:"posts"`
+}
+
+// User is a user fixture, the password is hashed when it is inserted
+type User struct {
+	Email    string `yaml:"email" json:"email"`
+	Name     string `yaml:"name" json:"name"`
+	Password string `yaml:"password" json:"password"`
+}
+
+// Category is a category fixture
+type Category struct {
+	Slug        string `yaml:"slug" json:"slug"`
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description" json:"description"`
+}
+
+// Post is a post fixture
+type Post struct {
+	Slug      string `yaml:"slug" json:"slug"`
+	Title     string `yaml:"title" json:"title"`
+	Content   string `yaml:"content" json:"content"`
+	Author    string `yaml:"author" json:"author"`
+	Category  string `yaml:"category" json:"category"`
+	Published bool   `yaml:"published" json:"published"`
+}
+
+// Result counts the records of one table handled by Apply
+type Result struct {
+	Table    string
+	Inserted int
+	Skipped  int
+}
+
+// ErrProduction is returned by CheckEnvironment for a production database
+var ErrProduction = errors.New("refusing to seed a production database, pass -force to override")
+
+// CheckEnvironment guards against applying development fixtures, with their
+// well-known passwords, to production. force is the explicit operator override.
+func CheckEnvironment(production, force bool) error {
+	if production && !force {
+		return ErrProduction
+	}
+	return nil
+}
+
+// slugPattern accepts lowercase words separated by single dashes
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// extensions are the supported fixture file formats
+var extensions = []string{".yaml", ".yml", ".json"}
+
+// LoadFile reads a fixture file, the format is picked by its extension
+// (.yaml, .yml or .json)
+func LoadFile(path string) (*Fixtures, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixtures: %w", err)
+	}
+
+	var fixtures Fixtures
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &fixtures)
+	case ".json":
+		err = json.Unmarshal(data, &fixtures)
+	default:
+		return nil, fmt.Errorf("unsupported fixture format %q", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &fixtures, nil
+}
+
+// LoadFiles reads and merges fixture files. Directories are expanded to the
+// fixture files they contain, in lexical order.
+func LoadFiles(paths ...string) (*Fixtures, error) {
+	merged := &Fixtures{}
+	for _, path := range paths {
+		files, err := expand(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			fixtures, err := LoadFile(file)
+			if err != nil {
+				return nil, err
+			}
+			merged.Users = append(merged.Users, fixtures.Users...)
+			merged.Categories = append(merged.Categories, fixtures.Categories...)
+			merged.Posts = append(merged.Posts, fixtures.Posts...)
+		}
+	}
+	return merged, nil
+}
+
+// expand returns path itself or the fixture files of a directory
+func expand(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixtures: %w", err)
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixtures: %w", err)
+	}
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && slices.Contains(extensions, strings.ToLower(filepath.Ext(entry.Name()))) {
+			files = append(files, filepath.Join(path, entry.Name()))
+		}
+	}
+	return files, nil
+}
+
+// Seeder applies fixtures to a database
+type Seeder struct {
+	db         *sql.DB
+	bcryptCost int
+}
+
+// New creates a seeder, bcryptCost is used to hash fixture passwords
+func New(db *sql.DB, bcryptCost int) *Seeder {
+	return &Seeder{db: db, bcryptCost: bcryptCost}
+}
+
+// Apply validates the fixtures and inserts them in a single transaction.
+// Nothing is written when any fixture is invalid or a reference is unknown.
+func (s *Seeder) Apply(ctx context.Context, fixtures *Fixtures) ([]Result, error) {
+	if err := fixtures.Validate(); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	users, err := s.insertUsers(ctx, tx, fixtures.Users)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := insertCategories(ctx, tx, fixtures.Categories)
+	if err != nil {
+		return nil, err
+	}
+	posts, err := insertPosts(ctx, tx, fixtures.Posts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit fixtures: %w", err)
+	}
+	return []Result{users, categories, posts}, nil
+}
+
+// Validate normalizes every fixture and reports all problems together
+func (f *Fixtures) Validate() error {
+	var errs []error
+
+	emails := make(map[string]bool)
+	for i := range f.Users {
+		u := &f.Users[i]
+		req := models.RegisterRequest{Email: u.Email, Name: u.Name, Password: u.Password}
+		req.Normalize()
+		if err := req.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("users[%d]: %w", i, err))
+			continue
+		}
+		if emails[req.Email] {
+			errs = append(errs, fmt.Errorf("users[%d]: duplicate email %s", i, req.Email))
+		}
+		emails[req.Email] = true
+		u.Email, u.Name = req.Email, req.Name
+	}
+
+	slugs := make(map[string]bool)
+	for i := range f.Categories {
+		c := &f.Categories[i]
+		c.Name = strings.TrimSpace(c.Name)
+		if err := validateSlug(c.Slug, slugs); err != nil {
+			errs = append(errs, fmt.Errorf("categories[%d]: %w", i, err))
+		}
+		if n := len([]rune(c.Name)); n < 1 || n > 100 {
+			errs = append(errs, fmt.Errorf("categories[%d]: name must be 1-100 characters", i))
+		}
+	}
+
+	slugs = make(map[string]bool)
+	for i := range f.Posts {
+		p := &f.Posts[i]
+		p.Title = strings.TrimSpace(p.Title)
+		p.Author = models.NormalizeEmail(p.Author)
+		if err := validateSlug(p.Slug, slugs); err != nil {
+			errs = append(errs, fmt.Errorf("posts[%d]: %w", i, err))
+		}
+		if n := len([]rune(p.Title)); n < 1 || n > 200 {
+			errs = append(errs, fmt.Errorf("posts[%d]: title must be 1-200 characters", i))
+		}
+		if strings.TrimSpace(p.Content) == "" {
+			errs = append(errs, fmt.Errorf("posts[%d]: content is required", i))
+		}
+		if p.Author == "" {
+			errs = append(errs, fmt.Errorf("posts[%d]: author is required", i))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateSlug checks the format of slug and that it was not seen before
+func validateSlug(slug string, seen map[string]bool) error {
+	if len(slug) > 100 || !slugPattern.MatchString(slug) {
+		return fmt.Errorf("invalid slug %q", slug)
+	}
+	if seen[slug] {
+		return fmt.Errorf("duplicate slug %s", slug)
+	}
+	seen[slug] = true
+	return nil
+}
+
+func (s *Seeder) insertUsers(ctx context.Context, tx *sql.Tx, users []User) (Result, error) {
+	result := Result{Table: "users"}
+	for _, u := range users {
+		hash, err := auth.HashPassword(u.Password, s.bcryptCost)
+		if err != nil {
+			return result, err
+		}
+		res, err := tx.ExecContext(ctx, `
+			INSERT INTO users (email, name, password_hash)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (email) DO NOTHING`,
+			u.Email, u.Name, hash,
+		)
+		if err != nil {
+			return result, fmt.Errorf("failed to insert user %s: %w", u.Email, err)
+		}
+		if err := countRow(&result, res); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+func insertCategories(ctx context.Context, tx *sql.Tx, categories []Category) (Result, error) {
+	result := Result{Table: "categories"}
+	for _, c := range categories {
+		res, err := tx.ExecContext(ctx, `
+			INSERT INTO categories (slug, name, description)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (slug) DO NOTHING`,
+			c.Slug, c.Name, c.Description,
+		)
+		if err != nil {
+			return result, fmt.Errorf("failed to insert category %s: %w", c.Slug, err)
+		}
+		if err := countRow(&result, res); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+func insertPosts(ctx context.Context, tx *sql.Tx, posts []Post) (Result, error) {
+	result := Result{Table: "posts"}
+	for _, p := range posts {
+		var userID int64
+		err := tx.QueryRowContext(ctx, `SELECT id FROM users WHERE email = $1`, p.Author).Scan(&userID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return result, fmt.Errorf("post %s: unknown author %s", p.Slug, p.Author)
+		}
+		if err != nil {
+			return result, fmt.Errorf("failed to look up author of post %s: %w", p.Slug, err)
+		}
+
+		var categoryID sql.NullInt64
+		if p.Category != "" {
+			err := tx.QueryRowContext(ctx, `SELECT id FROM categories WHERE slug = $1`, p.Category).Scan(&categoryID)
+			if errors.Is(err, sql.ErrNoRows) {
+				return result, fmt.Errorf("post %s: unknown category %s", p.Slug, p.Category)
+			}
+			if err != nil {
+				return result, fmt.Errorf("failed to look up category of post %s: %w", p.Slug, err)
+			}
+		}
+
+		res, err := tx.ExecContext(ctx, `
+			INSERT INTO posts (user_id, category_id, slug, title, content, published)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (slug) DO NOTHING`,
+			userID, categoryID, p.Slug, p.Title, p.Content, p.Published,
+		)
+		if err != nil {
+			return result, fmt.Errorf("failed to insert post %s: %w", p.Slug, err)
+		}
+		if err := countRow(&result, res); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// countRow records whether an insert created a row or hit a conflict
+func countRow(result *Result, res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		result.Skipped++
+	} else {
+		result.Inserted++
+	}
+	return nil
+}
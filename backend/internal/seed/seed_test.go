@@ -0,0 +1,242 @@
+package seed
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.Exec(`
+		CREATE TABLE users (
+			id INTEGER PRIMARY KEY,
+			email TEXT NOT NULL UNIQUE,
+			name TEXT NOT NULL,
+			password_hash TEXT NOT NULL
+		);
+		CREATE TABLE categories (
+			id INTEGER PRIMARY KEY,
+			slug TEXT NOT NULL UNIQUE,
+			name TEXT NOT NULL,
+			description TEXT NOT NULL
+		);
+		CREATE TABLE posts (
+			id INTEGER PRIMARY KEY,
+			user_id INTEGER NOT NULL REFERENCES users(id),
+			category_id INTEGER NULL REFERENCES categories(id),
+			slug TEXT NOT NULL UNIQUE,
+			title TEXT NOT NULL,
+			content TEXT NOT NULL,
+			published BOOLEAN NOT NULL
+		)`)
+	if err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+	return db
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write fixture: %v", err)
+	}
+	return path
+}
+
+func TestLoadFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"users.yaml", "users:\n  - email: a@example.com\n    name: Alice\n    password: password1\n"},
+		{"users.json", `{"users": [{"email": "a@example.com", "name": "Alice", "password": "password1"}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixtures, err := LoadFile(writeFile(t, tt.name, tt.content))
+			if err != nil {
+				t.Fatalf("LoadFile() failed: %v", err)
+			}
+			if len(fixtures.Users) != 1 || fixtures.Users[0].Email != "a@example.com" {
+				t.Errorf("Unexpected fixtures: %+v", fixtures)
+			}
+		})
+	}
+
+	if _, err := LoadFile(writeFile(t, "users.txt", "")); err == nil {
+		t.Error("Expected error for unsupported extension")
+	}
+}
+
+func TestApplyIsIdempotent(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	seeder := New(db, 4)
+	fixtures := &Fixtures{Users: []User{
+		{Email: " Alice@Example.com ", Name: "Alice", Password: "password1"},
+		{Email: "bob@example.com", Name: "Bob", Password: "password1"},
+	}}
+
+	results, err := seeder.Apply(ctx, fixtures)
+	if err != nil {
+		t.Fatalf("Apply() failed: %v", err)
+	}
+	if results[0].Inserted != 2 || results[0].Skipped != 0 {
+		t.Errorf("Expected 2 inserted users, got %+v", results[0])
+	}
+
+	results, err = seeder.Apply(ctx, fixtures)
+	if err != nil {
+		t.Fatalf("Second Apply() failed: %v", err)
+	}
+	if results[0].Inserted != 0 || results[0].Skipped != 2 {
+		t.Errorf("Expected 2 skipped users, got %+v", results[0])
+	}
+
+	var hash string
+	if err := db.QueryRow("SELECT password_hash FROM users WHERE email = 'alice@example.com'").Scan(&hash); err != nil {
+		t.Fatalf("Expected normalized email to be stored: %v", err)
+	}
+	if hash == "password1" {
+		t.Error("Password must be stored hashed")
+	}
+}
+
+func TestApplyRejectsInvalidFixtures(t *testing.T) {
+	db := newTestDB(t)
+	fixtures := &Fixtures{Users: []User{
+		{Email: "alice@example.com", Name: "Alice", Password: "password1"},
+		{Email: "not-an-email", Name: "Bob", Password: "password1"},
+	}}
+
+	if _, err := New(db, 4).Apply(context.Background(), fixtures); err == nil {
+		t.Fatal("Expected validation error")
+	}
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM users").Scan(&count); err != nil || count != 0 {
+		t.Errorf("Expected nothing to be written, got %d rows (%v)", count, err)
+	}
+}
+
+func TestLoadFilesMergesDirectory(t *testing.T) {
+	fixtures, err := LoadFiles("../../fixtures")
+	if err != nil {
+		t.Fatalf("LoadFiles() failed: %v", err)
+	}
+	if len(fixtures.Users) == 0 || len(fixtures.Categories) == 0 || len(fixtures.Posts) == 0 {
+		t.Errorf("Expected users, categories and posts, got %d/%d/%d",
+			len(fixtures.Users), len(fixtures.Categories), len(fixtures.Posts))
+	}
+	if err := fixtures.Validate(); err != nil {
+		t.Errorf("Development fixtures are invalid: %v", err)
+	}
+}
+
+func TestApplyPostsAndCategories(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	seeder := New(db, 4)
+	fixtures, err := LoadFiles("../../fixtures")
+	if err != nil {
+		t.Fatalf("LoadFiles() failed: %v", err)
+	}
+
+	results, err := seeder.Apply(ctx, fixtures)
+	if err != nil {
+		t.Fatalf("Apply() failed: %v", err)
+	}
+	for i, table := range []string{"users", "categories", "posts"} {
+		if results[i].Table != table || results[i].Inserted == 0 || results[i].Skipped != 0 {
+			t.Errorf("Unexpected result for %s: %+v", table, results[i])
+		}
+	}
+
+	var author, category string
+	err = db.QueryRow(`
+		SELECT u.email, c.slug FROM posts p
+		JOIN users u ON u.id = p.user_id
+		JOIN categories c ON c.id = p.category_id
+		WHERE p.slug = 'welcome'`).Scan(&author, &category)
+	if err != nil {
+		t.Fatalf("Failed to query post: %v", err)
+	}
+	if author != "admin@example.com" || category != "announcements" {
+		t.Errorf("Expected references to be resolved, got %s/%s", author, category)
+	}
+
+	results, err = seeder.Apply(ctx, fixtures)
+	if err != nil {
+		t.Fatalf("Second Apply() failed: %v", err)
+	}
+	for _, r := range results {
+		if r.Inserted != 0 {
+			t.Errorf("Expected nothing inserted on reseed, got %+v", r)
+		}
+	}
+}
+
+func TestApplyUnknownReference(t *testing.T) {
+	db := newTestDB(t)
+	fixtures := &Fixtures{Posts: []Post{
+		{Slug: "orphan", Title: "Orphan", Content: "text", Author: "nobody@example.com"},
+	}}
+
+	if _, err := New(db, 4).Apply(context.Background(), fixtures); err == nil {
+		t.Error("Expected error for unknown author")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		fixtures Fixtures
+	}{
+		{"invalid category slug", Fixtures{Categories: []Category{{Slug: "Not A Slug", Name: "X"}}}},
+		{"duplicate category", Fixtures{Categories: []Category{{Slug: "go", Name: "Go"}, {Slug: "go", Name: "Go"}}}},
+		{"missing title", Fixtures{Posts: []Post{{Slug: "p", Content: "c", Author: "a@example.com"}}}},
+		{"missing content", Fixtures{Posts: []Post{{Slug: "p", Title: "T", Author: "a@example.com"}}}},
+		{"missing author", Fixtures{Posts: []Post{{Slug: "p", Title: "T", Content: "c"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.fixtures.Validate(); err == nil {
+				t.Error("Expected validation error")
+			}
+		})
+	}
+}
+
+func TestCheckEnvironment(t *testing.T) {
+	tests := []struct {
+		name       string
+		production bool
+		force      bool
+		wantErr    bool
+	}{
+		{"development", false, false, false},
+		{"production", true, false, true},
+		{"production forced", true, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckEnvironment(tt.production, tt.force)
+			if tt.wantErr != errors.Is(err, ErrProduction) {
+				t.Errorf("CheckEnvironment(%v, %v) = %v", tt.production, tt.force, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+// Package seedtest gives every test its own Postgres schema, migrated to the
+// latest version and loaded with fixtures, so tests can run in parallel
+// against one server without seeing each other's data.
+package seedtest
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/pressly/goose/v3"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/database"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/migrate"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/seed"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/migrations"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// EnvDatabaseURL names the variable holding the Postgres server used by tests
+const EnvDatabaseURL = "TEST_DATABASE_URL"
+
+// NewDB creates a schema unique to the test, applies all migrations to it and
+// loads the fixture files or directories. The schema is dropped when the test
+// finishes. The test is skipped when TEST_DATABASE_URL is not set.
+func NewDB(t testing.TB, fixtures ...string) *sql.DB {
+	t.Helper()
+
+	baseURL := os.Getenv(EnvDatabaseURL)
+	if baseURL == "" {
+		t.Skipf("%s is not set", EnvDatabaseURL)
+	}
+	ctx := context.Background()
+
+	admin, err := sql.Open(database.DriverName, baseURL)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	schema := "test_" + randomSuffix(t)
+	if _, err := admin.ExecContext(ctx, `CREATE SCHEMA `+schema); err != nil {
+		admin.Close()
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.ExecContext(context.Background(), `DROP SCHEMA `+schema+` CASCADE`); err != nil {
+			t.Errorf("Failed to drop schema %s: %v", schema, err)
+		}
+		admin.Close()
+	})
+
+	db, err := database.Open(ctx, database.Config{URL: withSearchPath(t, baseURL, schema), MaxOpenConns: 4, MaxIdleConns: 4})
+	if err != nil {
+		t.Fatalf("Failed to connect to test schema: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := migrate.New(db, goose.DialectPostgres, migrations.FS)
+	if err != nil {
+		t.Fatalf("Failed to initialize migrations: %v", err)
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("Failed to migrate test schema: %v", err)
+	}
+
+	if len(fixtures) > 0 {
+		Load(t, db, fixtures...)
+	}
+	return db
+}
+
+// Load applies fixture files or directories to db
+func Load(t testing.TB, db *sql.DB, paths ...string) {
+	t.Helper()
+
+	fixtures, err := seed.LoadFiles(paths...)
+	if err != nil {
+		t.Fatalf("Failed to load fixtures: %v", err)
+	}
+	if _, err := seed.New(db, bcrypt.MinCost).Apply(context.Background(), fixtures); err != nil {
+		t.Fatalf("Failed to apply fixtures: %v", err)
+	}
+}
+
+// withSearchPath makes every connection use schema, public stays on the path
+// for extension types such as citext
+func withSearchPath(t testing.TB, dsn, schema string) string {
+	t.Helper()
+
+	searchPath := schema + ",public"
+	if !strings.HasPrefix(dsn, "postgres://") && !strings.HasPrefix(dsn, "postgresql://") {
+		return dsn + " search_path=" + searchPath
+	}
+	u, err := url.Parse(dsn)
+	if err != nil {
+		t.Fatalf("Invalid %s: %v", EnvDatabaseURL, err)
+	}
+	query := u.Query()
+	query.Set("search_path", searchPath)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+func randomSuffix(t testing.TB) string {
+	t.Helper()
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatalf("Failed to generate schema name: %v", err)
+	}
+	return hex.EncodeToString(b)
+}
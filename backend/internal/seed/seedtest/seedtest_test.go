@@ -0,0 +1,61 @@
+package seedtest
+
+import (
+	"testing"
+)
+
+func TestNewDB(t *testing.T) {
+	db := NewDB(t, "../../../fixtures")
+
+	tests := []struct {
+		table string
+		min   int
+	}{
+		{"users", 1},
+		{"categories", 1},
+		{"posts", 1},
+	}
+	for _, tt := range tests {
+		var count int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM ` + tt.table).Scan(&count); err != nil {
+			t.Fatalf("Failed to count %s: %v", tt.table, err)
+		}
+		if count < tt.min {
+			t.Errorf("Expected fixtures in %s, got %d rows", tt.table, count)
+		}
+	}
+
+	// Loading the same fixtures again is a no-op
+	Load(t, db, "../../../fixtures")
+}
+
+func TestSchemasAreIsolated(t *testing.T) {
+	first := NewDB(t)
+	second := NewDB(t, "../../../fixtures")
+
+	var count int
+	if err := first.QueryRow(`SELECT COUNT(*) FROM users`).Scan(&count); err != nil {
+		t.Fatalf("Failed to count users: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("Expected empty schema, got %d users", count)
+	}
+	if err := second.QueryRow(`SELECT COUNT(*) FROM users`).Scan(&count); err != nil || count == 0 {
+		t.Errorf("Expected seeded schema, got %d users (%v)", count, err)
+	}
+}
+
+func TestWithSearchPath(t *testing.T) {
+	tests := []struct {
+		dsn  string
+		want string
+	}{
+		{"postgres://u:p@localhost:5432/db?sslmode=disable", "postgres://u:p@localhost:5432/db?search_path=test_x%2Cpublic&sslmode=disable"},
+		{"host=localhost dbname=db", "host=localhost dbname=db search_path=test_x,public"},
+	}
+	for _, tt := range tests {
+		if got := withSearchPath(t, tt.dsn, "test_x"); got != tt.want {
+			t.Errorf("Expected %q, got %q", tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+// Package tlsutil builds the server TLS configuration and reloads
+// certificates when their files change
+package tlsutil
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// Supported minimum TLS versions
+const (
+	Version12 = "1.2"
+	Version13 = "1.3"
+)
+
+// Supported cipher policies, named after the Mozilla server side TLS profiles
+const (
+	// PolicyIntermediate allows TLS 1.2 with forward secret AEAD suites only
+	PolicyIntermediate = "intermediate"
+	// PolicyModern requires TLS 1.3, whose cipher suites are not configurable
+	PolicyModern = "modern"
+)
+
+// intermediateSuites are the TLS 1.2 suites of the intermediate policy
+var intermediateSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+}
+
+// ParseVersion converts "1.2" or "1.3" to the crypto/tls constant
+func ParseVersion(version string) (uint16, error) {
+	switch version {
+	case Version12:
+		return tls.VersionTLS12, nil
+	case Version13:
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported TLS version %q, use %s or %s", version, Version12, Version13)
+	}
+}
+
+// ValidatePolicy checks a cipher policy name
+func ValidatePolicy(policy string) error {
+	switch policy {
+	case PolicyIntermediate, PolicyModern:
+		return nil
+	default:
+		return fmt.Errorf("unknown cipher policy %q, use %s or %s", policy, PolicyIntermediate, PolicyModern)
+	}
+}
+
+// ServerConfig returns a TLS configuration serving the certificates of
+// getCert with HTTP/2 and HTTP/1.1 offered through ALPN
+func ServerConfig(minVersion, policy string, getCert func(*tls.ClientHelloInfo) (*tls.Certificate, error)) (*tls.Config, error) {
+	version, err := ParseVersion(minVersion)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidatePolicy(policy); err != nil {
+		return nil, err
+	}
+
+	cfg := &tls.Config{
+		MinVersion:     version,
+		GetCertificate: getCert,
+		NextProtos:     []string{"h2", "http/1.1"},
+	}
+	switch policy {
+	case PolicyModern:
+		cfg.MinVersion = tls.VersionTLS13
+	case PolicyIntermediate:
+		cfg.CipherSuites = intermediateSuites
+		cfg.CurvePreferences = []tls.CurveID{tls.X25519, tls.CurveP256, tls.CurveP384}
+	}
+	return cfg, nil
+}
+
+// RedirectHandler permanently redirects every request to the same URL over
+// HTTPS on httpsPort. The method and body are preserved (308).
+func RedirectHandler(httpsPort string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if httpsPort != "" && httpsPort != "443" {
+			host = net.JoinHostPort(host, httpsPort)
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	})
+}
@@ -0,0 +1,223 @@
+package tlsutil
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeSelfSigned writes a fresh self-signed certificate for 127.0.0.1 and
+// returns it parsed
+func writeSelfSigned(t *testing.T, certFile, keyFile, name string) *x509.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	// Written next to the target and renamed, the way rotations usually land
+	writeAtomic(t, keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+	writeAtomic(t, certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+
+	cert, _ := x509.ParseCertificate(der)
+	return cert
+}
+
+func writeAtomic(t *testing.T, path string, data []byte) {
+	t.Helper()
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		t.Fatalf("Failed to write %s: %v", tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatalf("Failed to rename %s: %v", tmp, err)
+	}
+}
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func servedSerial(t *testing.T, r *CertReloader) *big.Int {
+	t.Helper()
+	cert, _ := r.GetCertificate(nil)
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatalf("Failed to parse served certificate: %v", err)
+	}
+	return leaf.SerialNumber
+}
+
+func TestServerConfig(t *testing.T) {
+	getCert := func(*tls.ClientHelloInfo) (*tls.Certificate, error) { return nil, nil }
+
+	cfg, err := ServerConfig(Version12, PolicyIntermediate, getCert)
+	if err != nil {
+		t.Fatalf("ServerConfig() failed: %v", err)
+	}
+	if cfg.MinVersion != tls.VersionTLS12 || len(cfg.CipherSuites) == 0 {
+		t.Errorf("Unexpected intermediate config: min %x, %d suites", cfg.MinVersion, len(cfg.CipherSuites))
+	}
+	if cfg.NextProtos[0] != "h2" {
+		t.Errorf("Expected h2 to be offered first, got %v", cfg.NextProtos)
+	}
+
+	cfg, err = ServerConfig(Version12, PolicyModern, getCert)
+	if err != nil {
+		t.Fatalf("ServerConfig() failed: %v", err)
+	}
+	if cfg.MinVersion != tls.VersionTLS13 {
+		t.Error("Expected the modern policy to require TLS 1.3")
+	}
+
+	if _, err := ServerConfig("1.0", PolicyModern, getCert); err == nil {
+		t.Error("Expected TLS 1.0 to be rejected")
+	}
+	if _, err := ServerConfig(Version13, "legacy", getCert); err == nil {
+		t.Error("Expected an unknown policy to be rejected")
+	}
+}
+
+func TestRedirectHandler(t *testing.T) {
+	tests := []struct {
+		port   string
+		target string
+		want   string
+	}{
+		{"8443", "http://example.com:8080/api/v1/ping?x=1", "https://example.com:8443/api/v1/ping?x=1"},
+		{"443", "http://example.com/docs", "https://example.com/docs"},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		RedirectHandler(tt.port).ServeHTTP(w, httptest.NewRequest(http.MethodPost, tt.target, nil))
+		if w.Code != http.StatusPermanentRedirect {
+			t.Errorf("Expected 308, got %d", w.Code)
+		}
+		if got := w.Header().Get("Location"); got != tt.want {
+			t.Errorf("Expected redirect to %q, got %q", tt.want, got)
+		}
+	}
+}
+
+func TestHTTPSWithHTTP2AndReload(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	first := writeSelfSigned(t, certFile, keyFile, "first")
+
+	reloader, err := NewCertReloader(certFile, keyFile, discardLogger())
+	if err != nil {
+		t.Fatalf("NewCertReloader() failed: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go reloader.Watch(ctx)
+
+	tlsConfig, err := ServerConfig(Version12, PolicyIntermediate, reloader.GetCertificate)
+	if err != nil {
+		t.Fatalf("ServerConfig() failed: %v", err)
+	}
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, r.Proto)
+		}),
+		TLSConfig: tlsConfig,
+		ErrorLog:  slog.NewLogLogger(discardLogger().Handler(), slog.LevelError),
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	go server.ServeTLS(listener, "", "")
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(first)
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{RootCAs: roots},
+		ForceAttemptHTTP2: true,
+	}}
+
+	resp, err := client.Get("https://" + listener.Addr().String())
+	if err != nil {
+		t.Fatalf("HTTPS request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "HTTP/2.0" {
+		t.Errorf("Expected HTTP/2, got %s", body)
+	}
+
+	// Give the watcher time to register before rotating
+	time.Sleep(50 * time.Millisecond)
+	second := writeSelfSigned(t, certFile, keyFile, "second")
+
+	deadline := time.Now().Add(3 * time.Second)
+	for servedSerial(t, reloader).Cmp(second.SerialNumber) != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Certificate was not reloaded after the files changed")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestReloadKeepsCertificateOnError(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	first := writeSelfSigned(t, certFile, keyFile, "first")
+
+	reloader, err := NewCertReloader(certFile, keyFile, discardLogger())
+	if err != nil {
+		t.Fatalf("NewCertReloader() failed: %v", err)
+	}
+
+	if err := os.WriteFile(certFile, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := reloader.Reload(); err == nil {
+		t.Fatal("Expected Reload() to fail for a broken certificate")
+	}
+	if servedSerial(t, reloader).Cmp(first.SerialNumber) != 0 {
+		t.Error("Expected the previous certificate to be kept")
+	}
+
+	if _, err := NewCertReloader(filepath.Join(dir, "missing.crt"), keyFile, discardLogger()); err == nil {
+		t.Error("Expected NewCertReloader() to fail for a missing certificate")
+	}
+}
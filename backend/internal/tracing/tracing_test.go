@@ -0,0 +1,70 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRequestIDContext(t *testing.T) {
+	ctx := context.Background()
+	if got := RequestIDFromContext(ctx); got != "" {
+		t.Errorf("Expected no request ID, got %q", got)
+	}
+	if got := RequestIDFromContext(WithRequestID(ctx, "abc")); got != "abc" {
+		t.Errorf("Expected abc, got %q", got)
+	}
+	if id := NewRequestID(); len(id) != 32 || !ValidRequestID(id) {
+		t.Errorf("Expected valid 32 char ID, got %q", id)
+	}
+}
+
+func TestValidRequestID(t *testing.T) {
+	tests := []struct {
+		id    string
+		valid bool
+	}{
+		{"abc-123", true},
+		{"0f1e.2d:3c_4b", true},
+		{"", false},
+		{"has space", false},
+		{"line\nbreak", false},
+		{strings.Repeat("a", 129), false},
+	}
+
+	for _, tt := range tests {
+		if got := ValidRequestID(tt.id); got != tt.valid {
+			t.Errorf("ValidRequestID(%q) = %v, want %v", tt.id, got, tt.valid)
+		}
+	}
+}
+
+func TestSetupStdout(t *testing.T) {
+	var out bytes.Buffer
+	provider, err := Setup(context.Background(), Config{
+		ServiceName: "test",
+		Exporter:    ExporterStdout,
+		SampleRatio: 1,
+		Output:      &out,
+	})
+	if err != nil {
+		t.Fatalf("Setup() failed: %v", err)
+	}
+
+	_, span := Tracer().Start(context.Background(), "work")
+	span.End()
+	if err := provider.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() failed: %v", err)
+	}
+
+	if !strings.Contains(out.String(), `"Name":"work"`) {
+		t.Errorf("Expected span to be written to stdout exporter, got %q", out.String())
+	}
+}
+
+func TestSetupUnknownExporter(t *testing.T) {
+	if _, err := Setup(context.Background(), Config{Exporter: "zipkin"}); err == nil {
+		t.Error("Expected an error for an unknown exporter")
+	}
+}
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Case-insensitive text type used for emails and usernames
+CREATE EXTENSION IF NOT EXISTS citext;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP EXTENSION IF EXISTS citext;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Disabled users keep their data but can no longer log in or use their tokens
+ALTER TABLE users ADD COLUMN disabled_at TIMESTAMPTZ NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN IF EXISTS disabled_at;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+// Package migrations embeds the versioned SQL migrations of the backend
+// database so that every binary ships with the schema it expects.
+package migrations
+
+import "embed"
+
+// FS holds all goose-annotated SQL migration files
+//
+//go:embed *.sql
+var FS embed.FS
@@ -2,24 +2,20 @@ package calculator
 
 import (
 	"errors"
-	"fmt"
 	"strconv"
 )
 
 var ErrDivisionByZero = errors.New("division by zero")
 
 func Add(a, b float64) float64 {
-	fmt.Print(a + b)
 	return a + b
 }
 
 func Subtract(a, b float64) float64 {
-	fmt.Print(a - b)
 	return a - b
 }
 
 func Multiply(a, b float64) float64 {
-	fmt.Print(a * b)
 	return a * b
 }
 
@@ -27,7 +23,6 @@ func Divide(a, b float64) (float64, error) {
 	if b == 0 {
 		return 0, ErrDivisionByZero
 	}
-	fmt.Print(a / b)
 	return a / b, nil
 }
 
@@ -36,12 +31,9 @@ func StringToFloat(s string) (float64, error) {
 	if err != nil {
 		return 0, err
 	}
-	fmt.Print(float)
 	return float, nil
 }
 
 func FloatToString(f float64, precision int) string {
-	str := strconv.FormatFloat(f, 'f', precision, 64)
-	fmt.Print(str)
-	return str
+	return strconv.FormatFloat(f, 'f', precision, 64)
 }
@@ -0,0 +1,147 @@
+package calculator
+
+import (
+	"math"
+	"math/big"
+)
+
+// RoundingMode decides how digits beyond the precision are dropped
+type RoundingMode int
+
+const (
+	// RoundHalfEven rounds ties to the even neighbour (banker's rounding): 2.5 -> 2, 3.5 -> 4
+	RoundHalfEven RoundingMode = iota
+	// RoundHalfUp rounds ties away from zero: 2.5 -> 3, -2.5 -> -3
+	RoundHalfUp
+	// RoundTruncate drops the extra digits: 2.9 -> 2, -2.9 -> -2
+	RoundTruncate
+)
+
+// maxExactExponent bounds integer powers computed exactly, larger ones use float64
+const maxExactExponent = 1024
+
+// maxExactPowerBits bounds the size of an exact power, nested powers such as
+// (10^1000)^1000 would otherwise exhaust memory. Larger results use float64.
+const maxExactPowerBits = 1 << 16
+
+// Decimal is an exact decimal number returned by EvaluateDecimal
+type Decimal struct {
+	rat       *big.Rat
+	precision int
+}
+
+// String formats the decimal with exactly its precision of decimal places
+func (d Decimal) String() string {
+	return d.value().FloatString(d.precision)
+}
+
+// Rat returns a copy of the exact value
+func (d Decimal) Rat() *big.Rat {
+	return new(big.Rat).Set(d.value())
+}
+
+// Float64 returns the nearest float64
+func (d Decimal) Float64() float64 {
+	f, _ := d.value().Float64()
+	return f
+}
+
+// value returns the rational, the zero Decimal is 0
+func (d Decimal) value() *big.Rat {
+	if d.rat == nil {
+		return new(big.Rat)
+	}
+	return d.rat
+}
+
+// EvaluateDecimal computes expr like Evaluate with the decimal backend and
+// returns the exact rounded result, so "0.1 + 0.2" is exactly 0.3.
+// Precision and rounding are set with WithPrecision and WithRounding.
+func EvaluateDecimal(expr string, opts ...Option) (Decimal, error) {
+	s := newSettings(append(opts[:len(opts):len(opts)], WithBackend(BackendDecimal)))
+	result, err := evaluateDecimal(expr, s)
+	record(s.recorder, "evaluate_decimal", result, err, expr)
+	return result, err
+}
+
+func evaluateDecimal(expr string, s settings) (Decimal, error) {
+	if !validPrecision(s.precision) {
+		return Decimal{}, ErrInvalidPrecision
+	}
+	tree, err := parse(expr)
+	if err != nil {
+		return Decimal{}, err
+	}
+	r, err := tree.evalRat()
+	if err != nil {
+		return Decimal{}, err
+	}
+	return Decimal{rat: roundRat(r, s.precision, s.rounding), precision: s.precision}, nil
+}
+
+// roundRat rounds r to precision decimal places
+func roundRat(r *big.Rat, precision int, mode RoundingMode) *big.Rat {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(precision)), nil)
+	scaled := new(big.Rat).Mul(r, new(big.Rat).SetInt(scale))
+
+	// QuoRem truncates towards zero, the remainder has the sign of the numerator
+	quo, rem := new(big.Int).QuoRem(scaled.Num(), scaled.Denom(), new(big.Int))
+	if rem.Sign() != 0 && mode != RoundTruncate {
+		// Compare the dropped fraction |rem|/den with one half
+		twice := new(big.Int).Abs(rem)
+		twice.Lsh(twice, 1)
+		cmp := twice.Cmp(scaled.Denom())
+		if cmp > 0 || (cmp == 0 && (mode == RoundHalfUp || quo.Bit(0) == 1)) {
+			quo.Add(quo, big.NewInt(int64(rem.Sign())))
+		}
+	}
+	return new(big.Rat).SetFrac(quo, scale)
+}
+
+// roundFloat rounds f to precision decimal places using its exact binary value
+func roundFloat(f float64, precision int, mode RoundingMode) float64 {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return f
+	}
+	rounded, _ := roundRat(new(big.Rat).SetFloat64(f), precision, mode).Float64()
+	return rounded
+}
+
+// ratFromFloat converts the result of a float64 function, rejecting NaN and infinities
+func ratFromFloat(f float64) (*big.Rat, error) {
+	f, err := checkResult(f)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Rat).SetFloat64(f), nil
+}
+
+// powRat computes base^exponent exactly for integer exponents whose result fits maxExactPowerBits
+func powRat(base, exponent *big.Rat) (*big.Rat, error) {
+	if !exponent.IsInt() || exponent.Num().CmpAbs(big.NewInt(maxExactExponent)) > 0 {
+		return powFloat(base, exponent)
+	}
+	n := exponent.Num().Int64()
+	abs := max(n, -n)
+	// Numerator and denominator of the result have at most this many bits
+	if max(base.Num().BitLen(), base.Denom().BitLen())*int(abs) > maxExactPowerBits {
+		return powFloat(base, exponent)
+	}
+
+	if n < 0 && base.Sign() == 0 {
+		return nil, ErrDivisionByZero
+	}
+	num := new(big.Int).Exp(base.Num(), big.NewInt(abs), nil)
+	den := new(big.Int).Exp(base.Denom(), big.NewInt(abs), nil)
+	if n < 0 {
+		num, den = den, num
+	}
+	return new(big.Rat).SetFrac(num, den), nil
+}
+
+// powFloat computes base^exponent with float64, results outside its range are ErrDomain
+func powFloat(base, exponent *big.Rat) (*big.Rat, error) {
+	b, _ := base.Float64()
+	e, _ := exponent.Float64()
+	return ratFromFloat(math.Pow(b, e))
+}
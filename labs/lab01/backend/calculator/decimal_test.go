@@ -0,0 +1,147 @@
+package calculator
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestEvaluateDecimal(t *testing.T) {
+	tests := []struct {
+		name      string
+		expr      string
+		precision int
+		expected  string
+	}{
+		{"exact sum", "0.1 + 0.2", 2, "0.30"},
+		{"money", "19.99 * 3 - 0.97", 2, "59.00"},
+		{"repeating fraction", "1 / 3", 5, "0.33333"},
+		{"integer power", "1.1 ^ 2", 4, "1.2100"},
+		{"negative power", "2 ^ -2", 2, "0.25"},
+		{"modulo keeps sign of dividend", "-7.5 % 2", 1, "-1.5"},
+		{"exact abs and max", "max(abs(-0.1), 0.05)", 2, "0.10"},
+		{"function falls back to float", "sqrt(2.25)", 2, "1.50"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EvaluateDecimal(tt.expr, WithPrecision(tt.precision))
+			if err != nil {
+				t.Fatalf("EvaluateDecimal(%q) returned error: %v", tt.expr, err)
+			}
+			if got.String() != tt.expected {
+				t.Errorf("EvaluateDecimal(%q) = %s, want %s", tt.expr, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEvaluateBackends(t *testing.T) {
+	float, err := Evaluate("0.1 + 0.2")
+	if err != nil {
+		t.Fatalf("Evaluate() returned error: %v", err)
+	}
+	if float == 0.3 {
+		t.Error("Expected the float backend to keep the binary rounding error")
+	}
+
+	decimal, err := Evaluate("0.1 + 0.2", WithBackend(BackendDecimal))
+	if err != nil {
+		t.Fatalf("Evaluate() returned error: %v", err)
+	}
+	if decimal != 0.3 {
+		t.Errorf("Expected the decimal backend to return 0.3, got %v", decimal)
+	}
+}
+
+func TestRoundingModes(t *testing.T) {
+	tests := []struct {
+		expr     string
+		mode     RoundingMode
+		expected string
+	}{
+		{"2.5", RoundHalfEven, "2"},
+		{"3.5", RoundHalfEven, "4"},
+		{"-2.5", RoundHalfEven, "-2"},
+		{"2.5", RoundHalfUp, "3"},
+		{"-2.5", RoundHalfUp, "-3"},
+		{"2.4", RoundHalfUp, "2"},
+		{"2.9", RoundTruncate, "2"},
+		{"-2.9", RoundTruncate, "-2"},
+		{"2.51", RoundHalfEven, "3"},
+	}
+
+	for _, tt := range tests {
+		got, err := EvaluateDecimal(tt.expr, WithPrecision(0), WithRounding(tt.mode))
+		if err != nil {
+			t.Fatalf("EvaluateDecimal(%q) returned error: %v", tt.expr, err)
+		}
+		if got.String() != tt.expected {
+			t.Errorf("EvaluateDecimal(%q) with mode %d = %s, want %s", tt.expr, tt.mode, got, tt.expected)
+		}
+	}
+}
+
+func TestEvaluateDecimalPrecision(t *testing.T) {
+	got, err := Evaluate("2 / 3", WithDecimal(3, RoundHalfUp))
+	if err != nil {
+		t.Fatalf("Evaluate() returned error: %v", err)
+	}
+	if got != 0.667 {
+		t.Errorf("Expected 0.667, got %v", got)
+	}
+
+	got, err = Evaluate("2 / 3", WithPrecision(2), WithRounding(RoundTruncate))
+	if err != nil {
+		t.Fatalf("Evaluate() returned error: %v", err)
+	}
+	if got != 0.66 {
+		t.Errorf("Expected the float backend to round to 0.66, got %v", got)
+	}
+
+	d, err := EvaluateDecimal("1 / 8")
+	if err != nil {
+		t.Fatalf("EvaluateDecimal() returned error: %v", err)
+	}
+	if d.Rat().Cmp(big.NewRat(1, 8)) != 0 || d.Float64() != 0.125 {
+		t.Errorf("Expected exactly 1/8, got %s", d)
+	}
+
+	if _, err := Evaluate("1", WithPrecision(-1)); !errors.Is(err, ErrInvalidPrecision) {
+		t.Errorf("Expected ErrInvalidPrecision, got %v", err)
+	}
+	if _, err := Evaluate("1", WithPrecision(1<<30)); !errors.Is(err, ErrInvalidPrecision) {
+		t.Errorf("Expected ErrInvalidPrecision for a huge precision, got %v", err)
+	}
+	if _, err := EvaluateDecimal("1", WithPrecision(maxPrecision+1)); !errors.Is(err, ErrInvalidPrecision) {
+		t.Errorf("Expected ErrInvalidPrecision above maxPrecision, got %v", err)
+	}
+	if _, err := EvaluateDecimal("1 / 3", WithPrecision(maxPrecision)); err != nil {
+		t.Errorf("Expected maxPrecision to be accepted, got %v", err)
+	}
+}
+
+func TestEvaluateDecimalErrors(t *testing.T) {
+	tests := []struct {
+		expr     string
+		expected error
+	}{
+		{"1 / 0", ErrDivisionByZero},
+		{"1 % (0.5 - 0.5)", ErrDivisionByZero},
+		{"0 ^ -1", ErrDivisionByZero},
+		{"sqrt(-4)", ErrDomain},
+		{"((10 ^ 1000) ^ 1000) ^ 3", ErrDomain},
+		{"((10 ^ 1000) ^ 1000) ^ 1000 ^ 1000", ErrDomain},
+	}
+
+	for _, tt := range tests {
+		if _, err := EvaluateDecimal(tt.expr); !errors.Is(err, tt.expected) {
+			t.Errorf("EvaluateDecimal(%q) error = %v, want %v", tt.expr, err, tt.expected)
+		}
+	}
+
+	var syntaxErr *SyntaxError
+	if _, err := EvaluateDecimal("1 +"); !errors.As(err, &syntaxErr) {
+		t.Errorf("Expected *SyntaxError, got %v", err)
+	}
+}
@@ -0,0 +1,562 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ErrDomain is returned when a function argument or result is outside the real numbers
+var ErrDomain = errors.New("argument out of domain")
+
+// SyntaxError describes an invalid expression, Pos is the 1-based character position
+type SyntaxError struct {
+	Pos int
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at position %d: %s", e.Pos, e.Msg)
+}
+
+// Evaluate parses and computes an infix expression such as "2 * (3 + sqrt(16)) ^ 2".
+//
+// Supported are + - * / % ^ (power, right-associative), unary minus and plus,
+// parentheses, the constants pi and e and the functions listed in functions.
+// Invalid expressions return a *SyntaxError, dividing by zero returns
+// ErrDivisionByZero and results that are not real numbers return ErrDomain.
+//
+// Options select the backend per call: WithDecimal computes + - * / % and
+// integer powers exactly and rounds only the result, other functions are
+// computed with float64 in both backends.
+func Evaluate(expr string, opts ...Option) (float64, error) {
+	s := newSettings(opts)
+	result, err := evaluate(expr, s)
+	record(s.recorder, "evaluate", result, err, expr)
+	return result, err
+}
+
+func evaluate(expr string, s settings) (float64, error) {
+	if s.precisionSet && !validPrecision(s.precision) {
+		return 0, ErrInvalidPrecision
+	}
+	tree, err := parse(expr)
+	if err != nil {
+		return 0, err
+	}
+
+	if s.backend == BackendDecimal {
+		r, err := tree.evalRat()
+		if err != nil {
+			return 0, err
+		}
+		f, _ := roundRat(r, s.precision, s.rounding).Float64()
+		return f, nil
+	}
+
+	f, err := tree.eval()
+	if err != nil {
+		return 0, err
+	}
+	if s.precisionSet {
+		f = roundFloat(f, s.precision, s.rounding)
+	}
+	return f, nil
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokIdent
+	tokOperator
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	value float64
+	pos   int
+}
+
+// tokenize splits expr into tokens, positions are counted in runes from 1
+func tokenize(expr string) ([]token, error) {
+	runes := []rune(expr)
+	var tokens []token
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		pos := i + 1
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r) || r == '.':
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			// Exponent such as 1e-3, only when digits follow
+			if i < len(runes) && (runes[i] == 'e' || runes[i] == 'E') {
+				j := i + 1
+				if j < len(runes) && (runes[j] == '+' || runes[j] == '-') {
+					j++
+				}
+				if j < len(runes) && unicode.IsDigit(runes[j]) {
+					for j < len(runes) && unicode.IsDigit(runes[j]) {
+						j++
+					}
+					i = j
+				}
+			}
+			text := string(runes[start:i])
+			value, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, &SyntaxError{Pos: pos, Msg: fmt.Sprintf("invalid number %q", text)}
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: text, value: value, pos: pos})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: string(runes[start:i]), pos: pos})
+		case strings.ContainsRune("+-*/%^", r):
+			tokens = append(tokens, token{kind: tokOperator, text: string(r), pos: pos})
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokLParen, text: "(", pos: pos})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokRParen, text: ")", pos: pos})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{kind: tokComma, text: ",", pos: pos})
+			i++
+		default:
+			return nil, &SyntaxError{Pos: pos, Msg: fmt.Sprintf("unexpected character %q", r)}
+		}
+	}
+
+	return append(tokens, token{kind: tokEOF, pos: len(runes) + 1}), nil
+}
+
+// node is a parsed expression, evaluated with float64 or exact rationals
+type node interface {
+	eval() (float64, error)
+	evalRat() (*big.Rat, error)
+}
+
+type numberNode struct {
+	value float64
+	exact *big.Rat
+}
+
+type unaryNode struct {
+	op      string
+	operand node
+}
+
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+type callNode struct {
+	fn   function
+	args []node
+}
+
+func (n numberNode) eval() (float64, error) {
+	return n.value, nil
+}
+
+func (n unaryNode) eval() (float64, error) {
+	v, err := n.operand.eval()
+	if err != nil {
+		return 0, err
+	}
+	if n.op == "-" {
+		return -v, nil
+	}
+	return v, nil
+}
+
+func (n binaryNode) eval() (float64, error) {
+	a, err := n.left.eval()
+	if err != nil {
+		return 0, err
+	}
+	b, err := n.right.eval()
+	if err != nil {
+		return 0, err
+	}
+
+	switch n.op {
+	case "+":
+		return a + b, nil
+	case "-":
+		return a - b, nil
+	case "*":
+		return a * b, nil
+	case "/", "%":
+		if b == 0 {
+			return 0, ErrDivisionByZero
+		}
+		if n.op == "/" {
+			return a / b, nil
+		}
+		return math.Mod(a, b), nil
+	default:
+		return checkResult(math.Pow(a, b))
+	}
+}
+
+func (n callNode) eval() (float64, error) {
+	args := make([]float64, len(n.args))
+	for i, arg := range n.args {
+		v, err := arg.eval()
+		if err != nil {
+			return 0, err
+		}
+		args[i] = v
+	}
+	return checkResult(n.fn.call(args))
+}
+
+func (n numberNode) evalRat() (*big.Rat, error) {
+	return n.exact, nil
+}
+
+func (n unaryNode) evalRat() (*big.Rat, error) {
+	v, err := n.operand.evalRat()
+	if err != nil {
+		return nil, err
+	}
+	if n.op == "-" {
+		return new(big.Rat).Neg(v), nil
+	}
+	return v, nil
+}
+
+func (n binaryNode) evalRat() (*big.Rat, error) {
+	a, err := n.left.evalRat()
+	if err != nil {
+		return nil, err
+	}
+	b, err := n.right.evalRat()
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "+":
+		return new(big.Rat).Add(a, b), nil
+	case "-":
+		return new(big.Rat).Sub(a, b), nil
+	case "*":
+		return new(big.Rat).Mul(a, b), nil
+	case "/", "%":
+		if b.Sign() == 0 {
+			return nil, ErrDivisionByZero
+		}
+		quo := new(big.Rat).Quo(a, b)
+		if n.op == "/" {
+			return quo, nil
+		}
+		// Like math.Mod the result has the sign of a: a - b*trunc(a/b)
+		trunc := new(big.Int).Quo(quo.Num(), quo.Denom())
+		return new(big.Rat).Sub(a, new(big.Rat).Mul(b, new(big.Rat).SetInt(trunc))), nil
+	default:
+		return powRat(a, b)
+	}
+}
+
+func (n callNode) evalRat() (*big.Rat, error) {
+	args := make([]*big.Rat, len(n.args))
+	for i, arg := range n.args {
+		v, err := arg.evalRat()
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+	if n.fn.exact != nil {
+		return n.fn.exact(args), nil
+	}
+
+	floats := make([]float64, len(args))
+	for i, arg := range args {
+		floats[i], _ = arg.Float64()
+	}
+	return ratFromFloat(n.fn.call(floats))
+}
+
+// checkResult rejects NaN and infinities produced by math functions
+func checkResult(v float64) (float64, error) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, ErrDomain
+	}
+	return v, nil
+}
+
+// function is a named function callable from expressions, exact is used by
+// the decimal backend when the function can be computed without rounding
+type function struct {
+	arity int
+	call  func(args []float64) float64
+	exact func(args []*big.Rat) *big.Rat
+}
+
+func unary(f func(float64) float64) function {
+	return function{arity: 1, call: func(args []float64) float64 { return f(args[0]) }}
+}
+
+func binary(f func(float64, float64) float64) function {
+	return function{arity: 2, call: func(args []float64) float64 { return f(args[0], args[1]) }}
+}
+
+func withExact(fn function, exact func(args []*big.Rat) *big.Rat) function {
+	fn.exact = exact
+	return fn
+}
+
+// pick returns the smaller (sign -1) or larger (sign 1) of two rationals
+func pick(args []*big.Rat, sign int) *big.Rat {
+	if args[1].Cmp(args[0]) == sign {
+		return args[1]
+	}
+	return args[0]
+}
+
+// functions are available by name in expressions, log is base 10 and ln the natural logarithm
+var functions = map[string]function{
+	"sqrt":  unary(math.Sqrt),
+	"abs":   withExact(unary(math.Abs), func(args []*big.Rat) *big.Rat { return new(big.Rat).Abs(args[0]) }),
+	"sin":   unary(math.Sin),
+	"cos":   unary(math.Cos),
+	"tan":   unary(math.Tan),
+	"asin":  unary(math.Asin),
+	"acos":  unary(math.Acos),
+	"atan":  unary(math.Atan),
+	"ln":    unary(math.Log),
+	"log":   unary(math.Log10),
+	"log2":  unary(math.Log2),
+	"exp":   unary(math.Exp),
+	"floor": unary(math.Floor),
+	"ceil":  unary(math.Ceil),
+	"round": unary(math.Round),
+	"min":   withExact(binary(math.Min), func(args []*big.Rat) *big.Rat { return pick(args, -1) }),
+	"max":   withExact(binary(math.Max), func(args []*big.Rat) *big.Rat { return pick(args, 1) }),
+	"pow":   binary(math.Pow),
+}
+
+// constants are available by name in expressions
+var constants = map[string]float64{
+	"pi": math.Pi,
+	"e":  math.E,
+}
+
+// parser is a recursive descent parser over the grammar
+//
+//	expr    = term { ("+" | "-") term }
+//	term    = unary { ("*" | "/" | "%") unary }
+//	unary   = ("-" | "+") unary | power
+//	power   = primary [ "^" unary ]
+//	primary = number | name | name "(" [ expr { "," expr } ] ")" | "(" expr ")"
+//
+// so that -2^2 is -(2^2) and 2^-1 is allowed.
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func parse(expr string) (node, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	tree, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, unexpected(tok)
+	}
+	return tree, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// acceptOperator consumes the next token if it is one of ops
+func (p *parser) acceptOperator(ops string) (string, bool) {
+	tok := p.peek()
+	if tok.kind == tokOperator && strings.Contains(ops, tok.text) {
+		p.pos++
+		return tok.text, true
+	}
+	return "", false
+}
+
+func (p *parser) expr() (node, error) {
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.acceptOperator("+-")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) term() (node, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.acceptOperator("*/%")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) unary() (node, error) {
+	if op, ok := p.acceptOperator("+-"); ok {
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return unaryNode{op: op, operand: operand}, nil
+	}
+	return p.power()
+}
+
+func (p *parser) power() (node, error) {
+	base, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := p.acceptOperator("^"); !ok {
+		return base, nil
+	}
+	exponent, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	return binaryNode{op: "^", left: base, right: exponent}, nil
+}
+
+func (p *parser) primary() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokNumber:
+		exact, ok := new(big.Rat).SetString(tok.text)
+		if !ok {
+			return nil, &SyntaxError{Pos: tok.pos, Msg: fmt.Sprintf("invalid number %q", tok.text)}
+		}
+		return numberNode{value: tok.value, exact: exact}, nil
+	case tokLParen:
+		inner, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokRParen, "')'"); err != nil {
+			return nil, err
+		}
+		return inner, nil
+	case tokIdent:
+		if p.peek().kind == tokLParen {
+			return p.call(tok)
+		}
+		if value, ok := constants[tok.text]; ok {
+			return numberNode{value: value, exact: new(big.Rat).SetFloat64(value)}, nil
+		}
+		return nil, &SyntaxError{Pos: tok.pos, Msg: fmt.Sprintf("unknown identifier %q", tok.text)}
+	default:
+		return nil, unexpected(tok)
+	}
+}
+
+func (p *parser) call(name token) (node, error) {
+	fn, ok := functions[name.text]
+	if !ok {
+		return nil, &SyntaxError{Pos: name.pos, Msg: fmt.Sprintf("unknown function %q", name.text)}
+	}
+	p.next() // (
+
+	var args []node
+	if p.peek().kind != tokRParen {
+		for {
+			arg, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if p.peek().kind != tokComma {
+				break
+			}
+			p.next()
+		}
+	}
+	if err := p.expect(tokRParen, "')'"); err != nil {
+		return nil, err
+	}
+
+	if len(args) != fn.arity {
+		return nil, &SyntaxError{Pos: name.pos, Msg: fmt.Sprintf("%s expects %d argument(s), got %d", name.text, fn.arity, len(args))}
+	}
+	return callNode{fn: fn, args: args}, nil
+}
+
+func (p *parser) expect(kind tokenKind, what string) error {
+	tok := p.peek()
+	if tok.kind != kind {
+		return &SyntaxError{Pos: tok.pos, Msg: fmt.Sprintf("expected %s, got %s", what, describe(tok))}
+	}
+	p.next()
+	return nil
+}
+
+func unexpected(tok token) error {
+	return &SyntaxError{Pos: tok.pos, Msg: "unexpected " + describe(tok)}
+}
+
+func describe(tok token) string {
+	if tok.kind == tokEOF {
+		return "end of expression"
+	}
+	return fmt.Sprintf("%q", tok.text)
+}
@@ -0,0 +1,108 @@
+package calculator
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name     string
+		expr     string
+		expected float64
+	}{
+		{"number", "42", 42},
+		{"decimal", "3.5", 3.5},
+		{"exponent literal", "1.5e3", 1500},
+		{"precedence", "2 + 3 * 4", 14},
+		{"left associative", "10 - 4 - 3", 3},
+		{"division", "7 / 2", 3.5},
+		{"modulo", "10 % 4", 2},
+		{"parentheses", "(2 + 3) * 4", 20},
+		{"nested parentheses", "((1 + 2) * (3 + 4))", 21},
+		{"unary minus", "-3 + 5", 2},
+		{"double unary minus", "--3", 3},
+		{"unary minus in parentheses", "2 * -(1 + 2)", -6},
+		{"power", "2 ^ 10", 1024},
+		{"power is right associative", "2 ^ 3 ^ 2", 512},
+		{"power binds tighter than unary minus", "-2 ^ 2", -4},
+		{"negative exponent", "2 ^ -1", 0.5},
+		{"power before multiplication", "3 * 2 ^ 2", 12},
+		{"sqrt", "sqrt(16)", 4},
+		{"sin", "sin(0)", 0},
+		{"log", "log(1000)", 3},
+		{"ln", "ln(e)", 1},
+		{"two arguments", "max(2, 7) + min(2, 7)", 9},
+		{"nested calls", "sqrt(abs(-16)) * 2", 8},
+		{"constant", "2 * pi", 2 * math.Pi},
+		{"whitespace", "  1+\t2 ", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Evaluate(tt.expr)
+			if err != nil {
+				t.Fatalf("Evaluate(%q) returned error: %v", tt.expr, err)
+			}
+			if math.Abs(got-tt.expected) > 1e-9 {
+				t.Errorf("Evaluate(%q) = %v, want %v", tt.expr, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEvaluateSyntaxErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		pos  int
+	}{
+		{"empty", "", 1},
+		{"trailing operator", "1 +", 4},
+		{"missing closing parenthesis", "(1 + 2", 7},
+		{"extra closing parenthesis", "1 + 2)", 6},
+		{"unexpected character", "2 $ 3", 3},
+		{"two numbers", "1 2", 3},
+		{"unknown function", "foo(1)", 1},
+		{"unknown identifier", "1 + x", 5},
+		{"wrong argument count", "sqrt(1, 2)", 1},
+		{"invalid number", "1.2.3", 1},
+		{"empty parentheses", "()", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Evaluate(tt.expr)
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("Expected *SyntaxError, got %v", err)
+			}
+			if syntaxErr.Pos != tt.pos {
+				t.Errorf("Expected error at position %d, got %d (%v)", tt.pos, syntaxErr.Pos, err)
+			}
+		})
+	}
+}
+
+func TestEvaluateErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		expr     string
+		expected error
+	}{
+		{"division by zero", "1 / 0", ErrDivisionByZero},
+		{"division by zero expression", "1 / (2 - 2)", ErrDivisionByZero},
+		{"modulo by zero", "5 % 0", ErrDivisionByZero},
+		{"sqrt of negative", "sqrt(-1)", ErrDomain},
+		{"log of zero", "log(0)", ErrDomain},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Evaluate(tt.expr); !errors.Is(err, tt.expected) {
+				t.Errorf("Evaluate(%q) error = %v, want %v", tt.expr, err, tt.expected)
+			}
+		})
+	}
+}
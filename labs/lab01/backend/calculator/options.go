@@ -0,0 +1,84 @@
+package calculator
+
+import "errors"
+
+// ErrInvalidPrecision is returned when a precision below 0 or above maxPrecision is requested
+var ErrInvalidPrecision = errors.New("precision must be between 0 and 1000")
+
+// maxPrecision bounds the decimal places of a result, rounding builds 10^precision
+const maxPrecision = 1000
+
+// Backend selects the number representation used by Evaluate
+type Backend int
+
+const (
+	// BackendFloat computes with float64, fast but 0.1+0.2 is not 0.3
+	BackendFloat Backend = iota
+	// BackendDecimal computes with exact rationals and rounds only the result
+	BackendDecimal
+)
+
+// defaultDecimalPrecision is the number of decimal places kept by BackendDecimal
+// when no precision is given
+const defaultDecimalPrecision = 16
+
+// validPrecision reports whether precision is within 0 and maxPrecision
+func validPrecision(precision int) bool {
+	return precision >= 0 && precision <= maxPrecision
+}
+
+// Option configures a single Evaluate call
+type Option func(*settings)
+
+type settings struct {
+	backend      Backend
+	precision    int
+	precisionSet bool
+	rounding     RoundingMode
+	recorder     Recorder
+}
+
+func newSettings(opts []Option) settings {
+	s := settings{backend: BackendFloat, rounding: RoundHalfEven}
+	for _, opt := range opts {
+		opt(&s)
+	}
+	if s.backend == BackendDecimal && !s.precisionSet {
+		s.precision = defaultDecimalPrecision
+		s.precisionSet = true
+	}
+	return s
+}
+
+// WithBackend selects float64 or exact decimal arithmetic
+func WithBackend(backend Backend) Option {
+	return func(s *settings) {
+		s.backend = backend
+	}
+}
+
+// WithDecimal selects exact decimal arithmetic rounded to precision decimal places
+func WithDecimal(precision int, rounding RoundingMode) Option {
+	return func(s *settings) {
+		s.backend = BackendDecimal
+		s.precision = precision
+		s.precisionSet = true
+		s.rounding = rounding
+	}
+}
+
+// WithPrecision rounds the result to the given number of decimal places.
+// The float backend does not round unless a precision is given.
+func WithPrecision(precision int) Option {
+	return func(s *settings) {
+		s.precision = precision
+		s.precisionSet = true
+	}
+}
+
+// WithRounding sets how the result is rounded to the precision, half-even by default
+func WithRounding(rounding RoundingMode) Option {
+	return func(s *settings) {
+		s.rounding = rounding
+	}
+}
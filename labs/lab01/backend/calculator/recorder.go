@@ -0,0 +1,125 @@
+package calculator
+
+import (
+	"sync"
+	"time"
+)
+
+// Operation is a single recorded calculation
+type Operation struct {
+	Name   string
+	Inputs []any
+	Result any
+	Err    error
+	Time   time.Time
+}
+
+// Recorder observes calculations, for example to keep an audit log or history
+type Recorder interface {
+	Record(op Operation)
+}
+
+// RecorderFunc adapts a function to the Recorder interface
+type RecorderFunc func(op Operation)
+
+// Record calls f(op)
+func (f RecorderFunc) Record(op Operation) {
+	f(op)
+}
+
+// History is a Recorder that keeps every operation in memory, safe for concurrent use
+type History struct {
+	mu  sync.Mutex
+	ops []Operation
+}
+
+// Record appends op to the history
+func (h *History) Record(op Operation) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.ops = append(h.ops, op)
+}
+
+// Operations returns a copy of the recorded operations in call order
+func (h *History) Operations() []Operation {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]Operation(nil), h.ops...)
+}
+
+// WithRecorder reports every calculation to r
+func WithRecorder(r Recorder) Option {
+	return func(s *settings) {
+		s.recorder = r
+	}
+}
+
+// Calculator offers the package functions with a set of options applied,
+// every call is reported to the recorder given with WithRecorder
+type Calculator struct {
+	opts     []Option
+	recorder Recorder
+}
+
+// New creates a calculator, options are also applied to Evaluate and EvaluateDecimal
+func New(opts ...Option) *Calculator {
+	return &Calculator{opts: opts, recorder: newSettings(opts).recorder}
+}
+
+func (c *Calculator) Add(a, b float64) float64 {
+	result := Add(a, b)
+	c.record("add", result, nil, a, b)
+	return result
+}
+
+func (c *Calculator) Subtract(a, b float64) float64 {
+	result := Subtract(a, b)
+	c.record("subtract", result, nil, a, b)
+	return result
+}
+
+func (c *Calculator) Multiply(a, b float64) float64 {
+	result := Multiply(a, b)
+	c.record("multiply", result, nil, a, b)
+	return result
+}
+
+func (c *Calculator) Divide(a, b float64) (float64, error) {
+	result, err := Divide(a, b)
+	c.record("divide", result, err, a, b)
+	return result, err
+}
+
+func (c *Calculator) StringToFloat(s string) (float64, error) {
+	result, err := StringToFloat(s)
+	c.record("string_to_float", result, err, s)
+	return result, err
+}
+
+func (c *Calculator) FloatToString(f float64, precision int) string {
+	result := FloatToString(f, precision)
+	c.record("float_to_string", result, nil, f, precision)
+	return result
+}
+
+// Evaluate calls the package Evaluate with the calculator's options first
+func (c *Calculator) Evaluate(expr string, opts ...Option) (float64, error) {
+	return Evaluate(expr, append(c.opts[:len(c.opts):len(c.opts)], opts...)...)
+}
+
+// EvaluateDecimal calls the package EvaluateDecimal with the calculator's options first
+func (c *Calculator) EvaluateDecimal(expr string, opts ...Option) (Decimal, error) {
+	return EvaluateDecimal(expr, append(c.opts[:len(c.opts):len(c.opts)], opts...)...)
+}
+
+func (c *Calculator) record(name string, result any, err error, inputs ...any) {
+	record(c.recorder, name, result, err, inputs...)
+}
+
+// record reports an operation to r, a nil recorder is ignored
+func record(r Recorder, name string, result any, err error, inputs ...any) {
+	if r == nil {
+		return
+	}
+	r.Record(Operation{Name: name, Inputs: inputs, Result: result, Err: err, Time: time.Now()})
+}
@@ -0,0 +1,98 @@
+package calculator
+
+import (
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestCalculatorRecordsOperations(t *testing.T) {
+	history := &History{}
+	calc := New(WithRecorder(history))
+
+	calc.Add(1, 2)
+	calc.Subtract(5, 3)
+	calc.Multiply(2, 4)
+	if _, err := calc.Divide(1, 0); !errors.Is(err, ErrDivisionByZero) {
+		t.Errorf("Expected ErrDivisionByZero, got %v", err)
+	}
+	calc.StringToFloat("2.5")
+	calc.FloatToString(3.14159, 2)
+	calc.Evaluate("2 * (3 + 4)")
+	calc.EvaluateDecimal("0.1 + 0.2", WithPrecision(1))
+
+	expected := []struct {
+		name   string
+		inputs []any
+		result any
+	}{
+		{"add", []any{1.0, 2.0}, 3.0},
+		{"subtract", []any{5.0, 3.0}, 2.0},
+		{"multiply", []any{2.0, 4.0}, 8.0},
+		{"divide", []any{1.0, 0.0}, 0.0},
+		{"string_to_float", []any{"2.5"}, 2.5},
+		{"float_to_string", []any{3.14159, 2}, "3.14"},
+		{"evaluate", []any{"2 * (3 + 4)"}, 14.0},
+		{"evaluate_decimal", []any{"0.1 + 0.2"}, "0.3"},
+	}
+
+	ops := history.Operations()
+	if len(ops) != len(expected) {
+		t.Fatalf("Expected %d operations, got %d", len(expected), len(ops))
+	}
+	for i, want := range expected {
+		op := ops[i]
+		result := op.Result
+		if d, ok := result.(Decimal); ok {
+			result = d.String()
+		}
+		if op.Name != want.name || !reflect.DeepEqual(op.Inputs, want.inputs) || result != want.result {
+			t.Errorf("Operation %d = %s%v -> %v, want %s%v -> %v", i, op.Name, op.Inputs, result, want.name, want.inputs, want.result)
+		}
+		if op.Time.IsZero() {
+			t.Errorf("Operation %d has no time", i)
+		}
+	}
+	if !errors.Is(ops[3].Err, ErrDivisionByZero) {
+		t.Errorf("Expected divide error to be recorded, got %v", ops[3].Err)
+	}
+}
+
+func TestEvaluateWithRecorder(t *testing.T) {
+	var ops []Operation
+	recorder := RecorderFunc(func(op Operation) { ops = append(ops, op) })
+
+	if _, err := Evaluate("1 +", WithRecorder(recorder)); err == nil {
+		t.Fatal("Expected syntax error")
+	}
+	if len(ops) != 1 || ops[0].Name != "evaluate" || ops[0].Err == nil {
+		t.Errorf("Expected failed evaluation to be recorded, got %+v", ops)
+	}
+}
+
+func TestFunctionsDoNotPrint(t *testing.T) {
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	os.Stdout = w
+
+	Add(1, 2)
+	Subtract(1, 2)
+	Multiply(1, 2)
+	Divide(1, 2)
+	StringToFloat("1.5")
+	FloatToString(1.5, 1)
+	Evaluate("1 + 2")
+	New().Add(1, 2)
+
+	w.Close()
+	os.Stdout = stdout
+	out, _ := io.ReadAll(r)
+	if len(out) != 0 {
+		t.Errorf("Expected no output, got %q", out)
+	}
+}
@@ -1,3 +1,5 @@
 module lab01
 
 go 1.24
+
+require github.com/mattn/go-sqlite3 v1.14.22
@@ -0,0 +1,207 @@
+package taskmanager
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// csvColumns is the header written by Export, Import accepts the columns in any order and only requires title
+var csvColumns = []string{
+	"id", "parent_id", "title", "description", "done", "priority", "tags",
+	"due_at", "recurrence", "created_at", "updated_at", "completed_at",
+}
+
+// csvTagSeparator joins the tags of a task in a single cell, a separator or
+// backslash inside a tag is escaped with a backslash
+const csvTagSeparator = ';'
+
+var csvTagEscaper = strings.NewReplacer(`\`, `\\`, string(csvTagSeparator), `\`+string(csvTagSeparator))
+
+// joinCSVTags writes tags into one cell, splitCSVTags reverses it
+func joinCSVTags(tags []string) string {
+	escaped := make([]string, len(tags))
+	for i, tag := range tags {
+		escaped[i] = csvTagEscaper.Replace(tag)
+	}
+	return strings.Join(escaped, string(csvTagSeparator))
+}
+
+// splitCSVTags splits a cell written by joinCSVTags, a backslash keeps the next character as is
+func splitCSVTags(cell string) []string {
+	var tags []string
+	var tag strings.Builder
+	escaped := false
+	for _, r := range cell {
+		switch {
+		case escaped:
+			tag.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == csvTagSeparator:
+			tags = append(tags, tag.String())
+			tag.Reset()
+		default:
+			tag.WriteRune(r)
+		}
+	}
+	return append(tags, tag.String())
+}
+
+func exportCSV(w io.Writer, tasks []Task) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvColumns); err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		parentID := ""
+		if task.ParentID != 0 {
+			parentID = strconv.Itoa(task.ParentID)
+		}
+		record := []string{
+			strconv.Itoa(task.ID),
+			parentID,
+			task.Title,
+			task.Description,
+			strconv.FormatBool(task.Done),
+			task.Priority.String(),
+			joinCSVTags(task.Tags),
+			formatCSVTime(task.DueAt),
+			task.Recurrence,
+			formatCSVTime(&task.CreatedAt),
+			formatCSVTime(&task.UpdatedAt),
+			formatCSVTime(task.CompletedAt),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatCSVTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+// decodeCSV reads a CSV file with a header line, unknown columns are ignored
+func decodeCSV(r io.Reader) ([]*importRow, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("CSV file has no header")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := columns[name]; ok {
+			return nil, fmt.Errorf("CSV header has duplicate column %q", name)
+		}
+		columns[name] = i
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, errors.New("CSV header has no title column")
+	}
+
+	var rows []*importRow
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			row := &importRow{row: parseErr.StartLine, undecoded: true}
+			row.fail("", parseErr.Err)
+			rows = append(rows, row)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+
+		line, _ := cr.FieldPos(0)
+		row := &importRow{row: line}
+		rows = append(rows, row)
+		if len(record) != len(header) {
+			row.fail("", fmt.Errorf("expected %d fields, got %d", len(header), len(record)))
+			row.undecoded = true
+			continue
+		}
+		decodeCSVRecord(row, record, columns)
+	}
+}
+
+func decodeCSVRecord(row *importRow, record []string, columns map[string]int) {
+	value := func(name string) string {
+		if i, ok := columns[name]; ok {
+			return record[i]
+		}
+		return ""
+	}
+	ref := func(name string) string {
+		s := strings.TrimSpace(value(name))
+		if s == "" {
+			return ""
+		}
+		if id, err := strconv.Atoi(s); err != nil || id < 1 {
+			row.fail(name, fmt.Errorf("%q is not a task id", s))
+		}
+		return s
+	}
+	timestamp := func(name string) *time.Time {
+		s := strings.TrimSpace(value(name))
+		if s == "" {
+			return nil
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			row.fail(name, fmt.Errorf("%q is not an RFC 3339 time", s))
+			return nil
+		}
+		return &t
+	}
+
+	task := &row.task
+	row.ref = ref("id")
+	row.parentRef = ref("parent_id")
+	task.Title = value("title")
+	task.Description = value("description")
+	if s := strings.TrimSpace(value("done")); s != "" {
+		done, err := strconv.ParseBool(s)
+		if err != nil {
+			row.fail("done", fmt.Errorf("%q is not a boolean", s))
+		}
+		task.Done = done
+	}
+	priority, err := ParsePriority(value("priority"))
+	if err != nil {
+		row.fail("priority", err)
+	}
+	task.Priority = priority
+	if s := value("tags"); s != "" {
+		task.Tags = splitCSVTags(s)
+	}
+	task.DueAt = timestamp("due_at")
+	task.Recurrence = value("recurrence")
+	if t := timestamp("created_at"); t != nil {
+		task.CreatedAt = *t
+	}
+	if t := timestamp("updated_at"); t != nil {
+		task.UpdatedAt = *t
+	}
+	task.CompletedAt = timestamp("completed_at")
+}
@@ -0,0 +1,442 @@
+package taskmanager
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	icalProdID = "-//lab01//taskmanager//EN"
+	// icalUIDSuffix makes task IDs globally unique UIDs
+	icalUIDSuffix = "@taskmanager"
+	// icalRecurrence carries rules that RRULE cannot express, such as cron rules
+	icalRecurrence = "X-TASKMANAGER-RECURRENCE"
+	icalUTC        = "20060102T150405Z"
+	icalLocal      = "20060102T150405"
+	icalDate       = "20060102"
+	// icalLineLimit is the longest line in octets before it is folded, RFC 5545 section 3.1
+	icalLineLimit = 75
+)
+
+// icalFieldNames maps the task fields of import errors to VTODO properties
+var icalFieldNames = map[string]string{
+	"id":         "UID",
+	"parent_id":  "RELATED-TO",
+	"title":      "SUMMARY",
+	"priority":   "PRIORITY",
+	"recurrence": "RRULE",
+}
+
+// icalPriorities maps priorities to the 1 (highest) to 9 (lowest) scale of iCalendar, 0 means undefined
+var icalPriorities = map[Priority]int{
+	PriorityNone:   0,
+	PriorityLow:    7,
+	PriorityMedium: 5,
+	PriorityHigh:   3,
+	PriorityUrgent: 1,
+}
+
+func exportICal(w io.Writer, tasks []Task, now time.Time) error {
+	bw := bufio.NewWriter(w)
+	write := func(name, value string) {
+		writeICalLine(bw, name+":"+value)
+	}
+
+	write("BEGIN", "VCALENDAR")
+	write("VERSION", "2.0")
+	write("PRODID", icalProdID)
+	for _, task := range tasks {
+		write("BEGIN", "VTODO")
+		write("UID", icalUID(task.ID))
+		write("DTSTAMP", now.UTC().Format(icalUTC))
+		write("CREATED", task.CreatedAt.UTC().Format(icalUTC))
+		write("LAST-MODIFIED", task.UpdatedAt.UTC().Format(icalUTC))
+		write("SUMMARY", escapeICalText(task.Title))
+		if task.Description != "" {
+			write("DESCRIPTION", escapeICalText(task.Description))
+		}
+		if task.Done {
+			write("STATUS", "COMPLETED")
+		} else {
+			write("STATUS", "NEEDS-ACTION")
+		}
+		if task.CompletedAt != nil {
+			write("COMPLETED", task.CompletedAt.UTC().Format(icalUTC))
+		}
+		if p := icalPriorities[task.Priority]; p != 0 {
+			write("PRIORITY", strconv.Itoa(p))
+		}
+		if len(task.Tags) > 0 {
+			escaped := make([]string, len(task.Tags))
+			for i, tag := range task.Tags {
+				escaped[i] = escapeICalText(tag)
+			}
+			write("CATEGORIES", strings.Join(escaped, ","))
+		}
+		if task.DueAt != nil {
+			write("DUE", task.DueAt.UTC().Format(icalUTC))
+		}
+		if task.ParentID != 0 {
+			write("RELATED-TO;RELTYPE=PARENT", icalUID(task.ParentID))
+		}
+		if task.Recurrence != "" {
+			if rrule, ok := icalRRule(task.Recurrence); ok {
+				write("RRULE", rrule)
+			} else {
+				write(icalRecurrence, escapeICalText(task.Recurrence))
+			}
+		}
+		write("END", "VTODO")
+	}
+	write("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+func icalUID(id int) string {
+	return fmt.Sprintf("task-%d%s", id, icalUIDSuffix)
+}
+
+// writeICalLine folds lines longer than icalLineLimit octets without splitting UTF-8 sequences
+func writeICalLine(w *bufio.Writer, line string) {
+	limit := icalLineLimit
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.WriteString(line[:cut])
+		w.WriteString("\r\n ")
+		line = line[cut:]
+		// The leading space of a continuation line counts towards the limit
+		limit = icalLineLimit - 1
+	}
+	w.WriteString(line)
+	w.WriteString("\r\n")
+}
+
+// icalRRule converts day and week intervals to an RRULE, other rules have no RRULE equivalent here
+func icalRRule(rule string) (string, bool) {
+	parsed, err := ParseRecurrence(rule)
+	if err != nil {
+		return "", false
+	}
+	interval, ok := parsed.(intervalRule)
+	if !ok {
+		return "", false
+	}
+	freq, n := "DAILY", interval.days
+	if n%7 == 0 {
+		freq, n = "WEEKLY", n/7
+	}
+	if n == 1 {
+		return "FREQ=" + freq, true
+	}
+	return fmt.Sprintf("FREQ=%s;INTERVAL=%d", freq, n), true
+}
+
+// parseICalRRule converts a daily or weekly RRULE to a recurrence rule
+func parseICalRRule(value string) (string, error) {
+	freq, interval := "", 1
+	for _, part := range strings.Split(value, ";") {
+		name, val, _ := strings.Cut(part, "=")
+		switch strings.ToUpper(name) {
+		case "FREQ":
+			freq = strings.ToUpper(val)
+		case "INTERVAL":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return "", fmt.Errorf("%w: invalid INTERVAL %q", ErrInvalidRecurrence, val)
+			}
+			interval = n
+		default:
+			return "", fmt.Errorf("%w: unsupported RRULE part %q", ErrInvalidRecurrence, part)
+		}
+	}
+	switch freq {
+	case "DAILY":
+		return fmt.Sprintf("every %d days", interval), nil
+	case "WEEKLY":
+		return fmt.Sprintf("every %d weeks", interval), nil
+	}
+	return "", fmt.Errorf("%w: unsupported FREQ %q", ErrInvalidRecurrence, freq)
+}
+
+var icalEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func escapeICalText(s string) string {
+	return icalEscaper.Replace(s)
+}
+
+func unescapeICalText(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// splitICalList splits a comma separated TEXT list, escaped commas stay in their value
+func splitICalList(s string) []string {
+	var values []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case ',':
+			values = append(values, unescapeICalText(s[start:i]))
+			start = i + 1
+		}
+	}
+	return append(values, unescapeICalText(s[start:]))
+}
+
+// icalProperty is one unfolded content line
+type icalProperty struct {
+	line   int
+	name   string
+	params map[string]string
+	value  string
+}
+
+// parseICalLine splits "NAME;PARAM=VALUE:value", parameter values may be quoted
+func parseICalLine(line int, s string) (icalProperty, error) {
+	prop := icalProperty{line: line, params: make(map[string]string)}
+	i := strings.IndexAny(s, ";:")
+	if i <= 0 {
+		return prop, fmt.Errorf("malformed content line %q", s)
+	}
+	prop.name = strings.ToUpper(s[:i])
+
+	for s[i] == ';' {
+		s = s[i+1:]
+		eq := strings.IndexByte(s, '=')
+		if eq <= 0 {
+			return prop, fmt.Errorf("malformed parameter in %s", prop.name)
+		}
+		key := strings.ToUpper(s[:eq])
+		s = s[eq+1:]
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			end := strings.IndexByte(s[1:], '"')
+			if end < 0 {
+				return prop, fmt.Errorf("unterminated parameter %s in %s", key, prop.name)
+			}
+			value, s = s[1:end+1], s[end+2:]
+			i = 0
+		} else {
+			i = strings.IndexAny(s, ";:")
+			if i < 0 {
+				return prop, fmt.Errorf("missing value in %s", prop.name)
+			}
+			value = s[:i]
+		}
+		prop.params[key] = value
+		if i >= len(s) {
+			return prop, fmt.Errorf("missing value in %s", prop.name)
+		}
+	}
+	if s[i] != ':' {
+		return prop, fmt.Errorf("missing value in %s", prop.name)
+	}
+	prop.value = s[i+1:]
+	return prop, nil
+}
+
+// time parses DATE-TIME values in UTC, with a TZID or floating in local time, and DATE values as local midnight
+func (p icalProperty) time() (time.Time, error) {
+	loc := time.Local
+	if tzid := p.params["TZID"]; tzid != "" {
+		var err error
+		if loc, err = time.LoadLocation(tzid); err != nil {
+			return time.Time{}, fmt.Errorf("unknown time zone %q", tzid)
+		}
+	}
+	layouts := []string{icalLocal, icalDate}
+	if strings.HasSuffix(p.value, "Z") {
+		layouts, loc = []string{icalUTC}, time.UTC
+	}
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, p.value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not a date or date-time", p.value)
+}
+
+// unfoldICal returns the logical content lines of r with the physical line each starts on
+func unfoldICal(r io.Reader) ([]icalProperty, []error, error) {
+	type logical struct {
+		line int
+		text string
+	}
+	var lines []logical
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		text := strings.TrimSuffix(scanner.Text(), "\r")
+		if (strings.HasPrefix(text, " ") || strings.HasPrefix(text, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1].text += text[1:]
+			continue
+		}
+		if text != "" {
+			lines = append(lines, logical{n, text})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to read iCalendar: %w", err)
+	}
+
+	props := make([]icalProperty, len(lines))
+	errs := make([]error, len(lines))
+	for i, l := range lines {
+		props[i], errs[i] = parseICalLine(l.line, l.text)
+	}
+	return props, errs, nil
+}
+
+// decodeICal reads every VTODO of r, other components and nested components such as VALARM are skipped
+func decodeICal(r io.Reader) ([]*importRow, error) {
+	props, errs, err := unfoldICal(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(props) == 0 || errs[0] != nil || props[0].name != "BEGIN" || !strings.EqualFold(props[0].value, "VCALENDAR") {
+		return nil, errors.New("not an iCalendar file, it must start with BEGIN:VCALENDAR")
+	}
+
+	var (
+		rows   []*importRow
+		row    *importRow
+		rrule  *icalProperty
+		nested int
+	)
+	for i, prop := range props {
+		switch {
+		case row == nil:
+			if errs[i] == nil && prop.name == "BEGIN" && strings.EqualFold(prop.value, "VTODO") {
+				row = &importRow{row: prop.line}
+				rrule = nil
+			}
+		case errs[i] != nil:
+			row.fail("", fmt.Errorf("line %d: %w", prop.line, errs[i]))
+		case prop.name == "BEGIN":
+			nested++
+		case prop.name == "END" && nested > 0:
+			nested--
+		case prop.name == "END":
+			decodeICalRRule(row, rrule)
+			rows = append(rows, row)
+			row = nil
+		case nested == 0 && prop.name == "RRULE":
+			rrule = &props[i]
+		case nested == 0:
+			decodeICalProperty(row, prop)
+		}
+	}
+	if row != nil {
+		decodeICalRRule(row, rrule)
+		row.fail("", errors.New("missing END:VTODO"))
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// decodeICalRRule applies the RRULE of a VTODO once all of its properties are read.
+// Our own X-TASKMANAGER-RECURRENCE is exact and wins, an RRULE we cannot convert
+// is only an error when there is no such property to fall back to.
+func decodeICalRRule(row *importRow, rrule *icalProperty) {
+	if rrule == nil || row.task.Recurrence != "" {
+		return
+	}
+	rule, err := parseICalRRule(rrule.value)
+	if err != nil {
+		row.fail(rrule.name, err)
+		return
+	}
+	row.task.Recurrence = rule
+}
+
+func decodeICalProperty(row *importRow, prop icalProperty) {
+	task := &row.task
+	timestamp := func() *time.Time {
+		t, err := prop.time()
+		if err != nil {
+			row.fail(prop.name, err)
+			return nil
+		}
+		return &t
+	}
+
+	switch prop.name {
+	case "UID":
+		row.ref = prop.value
+	case "RELATED-TO":
+		if reltype := strings.ToUpper(prop.params["RELTYPE"]); reltype == "" || reltype == "PARENT" {
+			row.parentRef = prop.value
+		}
+	case "SUMMARY":
+		task.Title = unescapeICalText(prop.value)
+	case "DESCRIPTION":
+		task.Description = unescapeICalText(prop.value)
+	case "STATUS":
+		task.Done = strings.EqualFold(prop.value, "COMPLETED")
+	case "COMPLETED":
+		task.CompletedAt = timestamp()
+		if task.CompletedAt != nil {
+			task.Done = true
+		}
+	case "PRIORITY":
+		p, err := strconv.Atoi(prop.value)
+		if err != nil || p < 0 || p > 9 {
+			row.fail(prop.name, fmt.Errorf("%w: %q is not between 0 and 9", ErrInvalidPriority, prop.value))
+			return
+		}
+		task.Priority = priorityFromICal(p)
+	case "CATEGORIES":
+		task.Tags = append(task.Tags, splitICalList(prop.value)...)
+	case "DUE":
+		task.DueAt = timestamp()
+	case "CREATED":
+		if t := timestamp(); t != nil {
+			task.CreatedAt = *t
+		}
+	case "LAST-MODIFIED":
+		if t := timestamp(); t != nil {
+			task.UpdatedAt = *t
+		}
+	case icalRecurrence:
+		task.Recurrence = unescapeICalText(prop.value)
+	}
+}
+
+// priorityFromICal maps 1-2 to urgent, 3-4 to high, 5 to medium and 6-9 to low
+func priorityFromICal(p int) Priority {
+	switch {
+	case p == 0:
+		return PriorityNone
+	case p <= 2:
+		return PriorityUrgent
+	case p <= 4:
+		return PriorityHigh
+	case p == 5:
+		return PriorityMedium
+	}
+	return PriorityLow
+}
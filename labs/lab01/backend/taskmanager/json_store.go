@@ -0,0 +1,142 @@
+package taskmanager
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// JSONStore keeps tasks in memory and writes the whole set to a JSON file
+// after every change. The file is replaced atomically, so a crash leaves
+// either the old or the new content. It must not be shared between processes.
+type JSONStore struct {
+	path string
+	mu   sync.Mutex
+	mem  *MemoryStore
+}
+
+// jsonFile is the on-disk format, NextID makes IDs survive restarts
+type jsonFile struct {
+	NextID int    `json:"next_id"`
+	Tasks  []Task `json:"tasks"`
+}
+
+// OpenJSONStore loads the tasks in path, a missing file is an empty store
+func OpenJSONStore(path string) (*JSONStore, error) {
+	mem := NewMemoryStore()
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read task file: %w", err)
+	}
+	if err == nil {
+		var file jsonFile
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("failed to parse task file %s: %w", path, err)
+		}
+		for _, task := range file.Tasks {
+			mem.put(task)
+		}
+		mem.nextID = max(mem.nextID, file.NextID)
+	}
+
+	return &JSONStore{path: path, mem: mem}, nil
+}
+
+func (s *JSONStore) Create(task Task) (Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	created, err := s.mem.Create(task)
+	if err != nil {
+		return Task{}, err
+	}
+	if err := s.save(); err != nil {
+		s.mem.Delete(created.ID)
+		return Task{}, err
+	}
+	return created, nil
+}
+
+func (s *JSONStore) Get(id int) (Task, error) {
+	return s.mem.Get(id)
+}
+
+func (s *JSONStore) Update(id int, fn func(task *Task) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, err := s.mem.Get(id)
+	if err != nil {
+		return err
+	}
+	if err := s.mem.Update(id, fn); err != nil {
+		return err
+	}
+	if err := s.save(); err != nil {
+		s.mem.put(old)
+		return err
+	}
+	return nil
+}
+
+func (s *JSONStore) Delete(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, err := s.mem.Get(id)
+	if err != nil {
+		return err
+	}
+	if err := s.mem.Delete(id); err != nil {
+		return err
+	}
+	if err := s.save(); err != nil {
+		s.mem.put(old)
+		return err
+	}
+	return nil
+}
+
+func (s *JSONStore) List() ([]Task, error) {
+	return s.mem.List()
+}
+
+func (s *JSONStore) Close() error {
+	return nil
+}
+
+// save writes all tasks to a temporary file and renames it over path
+func (s *JSONStore) save() error {
+	tasks, nextID := s.mem.snapshot()
+	file := jsonFile{NextID: nextID, Tasks: tasks}
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode tasks: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write task file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write task file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write task file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write task file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to write task file: %w", err)
+	}
+	return nil
+}
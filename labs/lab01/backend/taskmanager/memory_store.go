@@ -0,0 +1,103 @@
+package taskmanager
+
+import (
+	"sort"
+	"sync"
+)
+
+// MemoryStore keeps tasks in a map guarded by a mutex, tasks are lost on exit
+type MemoryStore struct {
+	mu     sync.RWMutex
+	tasks  map[int]Task
+	nextID int
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		tasks:  make(map[int]Task),
+		nextID: 1,
+	}
+}
+
+func (s *MemoryStore) Create(task Task) (Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task.ID = s.nextID
+	s.nextID++
+	s.tasks[task.ID] = task.clone()
+	return task.clone(), nil
+}
+
+func (s *MemoryStore) Get(id int) (Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	task, exists := s.tasks[id]
+	if !exists {
+		return Task{}, ErrTaskNotFound
+	}
+	return task.clone(), nil
+}
+
+func (s *MemoryStore) Update(id int, fn func(task *Task) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, exists := s.tasks[id]
+	if !exists {
+		return ErrTaskNotFound
+	}
+	task = task.clone()
+	if err := fn(&task); err != nil {
+		return err
+	}
+	task.ID = id
+	s.tasks[id] = task.clone()
+	return nil
+}
+
+func (s *MemoryStore) Delete(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.tasks[id]; !exists {
+		return ErrTaskNotFound
+	}
+	delete(s.tasks, id)
+	return nil
+}
+
+func (s *MemoryStore) List() ([]Task, error) {
+	tasks, _ := s.snapshot()
+	return tasks, nil
+}
+
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+// put stores task under its ID without allocating one, used to load and restore tasks
+func (s *MemoryStore) put(task Task) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tasks[task.ID] = task.clone()
+	s.nextID = max(s.nextID, task.ID+1)
+}
+
+// snapshot returns all tasks and the next ID consistently
+func (s *MemoryStore) snapshot() ([]Task, int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]Task, 0, len(s.tasks))
+	for _, task := range s.tasks {
+		result = append(result, task.clone())
+	}
+	sortByID(result)
+	return result, s.nextID
+}
+
+func sortByID(tasks []Task) {
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+}
@@ -0,0 +1,278 @@
+package taskmanager
+
+import (
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrInvalidQuery is returned for an unknown sort field, a negative limit or a cursor that does not fit the query
+var ErrInvalidQuery = errors.New("invalid query")
+
+// SortField names a task field ListTasks can sort by
+type SortField string
+
+const (
+	SortByID        SortField = "id"
+	SortByTitle     SortField = "title"
+	SortByPriority  SortField = "priority"
+	SortByDueAt     SortField = "due_at"
+	SortByCreatedAt SortField = "created_at"
+	SortByUpdatedAt SortField = "updated_at"
+)
+
+// SortKey is one level of a multi-key sort
+type SortKey struct {
+	Field SortField
+	Desc  bool
+}
+
+// ParseSort parses a comma separated list of fields such as "-priority,due_at",
+// a leading "-" sorts that field in descending order
+func ParseSort(s string) ([]SortKey, error) {
+	var keys []SortKey
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key := SortKey{Field: SortField(strings.TrimPrefix(part, "-")), Desc: strings.HasPrefix(part, "-")}
+		if !key.Field.valid() {
+			return nil, fmt.Errorf("%w: unknown sort field %q", ErrInvalidQuery, key.Field)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (f SortField) valid() bool {
+	switch f {
+	case SortByID, SortByTitle, SortByPriority, SortByDueAt, SortByCreatedAt, SortByUpdatedAt:
+		return true
+	}
+	return false
+}
+
+// TaskQuery selects, orders and pages the tasks returned by ListTasks.
+// Zero fields do not filter, so the zero query returns every task ordered by ID.
+type TaskQuery struct {
+	// Done keeps only done or only open tasks
+	Done *bool
+	// Tags keeps tasks that have all of these tags
+	Tags []string
+	// Priorities keeps tasks with any of these priorities
+	Priorities []Priority
+	// DueBefore and DueAfter keep tasks due strictly before or after the given time,
+	// tasks without a due date never match them
+	DueBefore *time.Time
+	DueAfter  *time.Time
+	// Search keeps tasks whose title or description contains it, ignoring case
+	Search string
+
+	// Sort orders the result, ties are always broken by ID so the order is stable
+	Sort []SortKey
+	// Limit caps the number of tasks in a page, zero means no limit
+	Limit int
+	// Cursor continues after the page that returned it as NextCursor
+	Cursor string
+}
+
+// TaskPage is one page of a ListTasks result
+type TaskPage struct {
+	Tasks []Task
+	// Total counts every task matching the filters, across all pages
+	Total int
+	// NextCursor is empty on the last page
+	NextCursor string
+}
+
+// ListTasks returns the tasks matching q in the requested order, one page at a time,
+// returns ErrInvalidQuery if the sort, limit or cursor is invalid
+func (tm *TaskManager) ListTasks(q TaskQuery) (TaskPage, error) {
+	if q.Limit < 0 {
+		return TaskPage{}, fmt.Errorf("%w: negative limit", ErrInvalidQuery)
+	}
+	for _, key := range q.Sort {
+		if !key.Field.valid() {
+			return TaskPage{}, fmt.Errorf("%w: unknown sort field %q", ErrInvalidQuery, key.Field)
+		}
+	}
+	var after *Task
+	if q.Cursor != "" {
+		pivot, err := decodeCursor(q.Cursor, q.Sort)
+		if err != nil {
+			return TaskPage{}, err
+		}
+		after = &pivot
+	}
+
+	tasks, err := tm.store.List()
+	if err != nil {
+		return TaskPage{}, err
+	}
+
+	matched := []Task{}
+	for _, task := range tasks {
+		if q.matches(task) {
+			matched = append(matched, task)
+		}
+	}
+	slices.SortFunc(matched, func(a, b Task) int {
+		return compareTasks(a, b, q.Sort)
+	})
+
+	page := TaskPage{Total: len(matched)}
+	start := 0
+	if after != nil {
+		start, _ = slices.BinarySearchFunc(matched, *after, func(task, pivot Task) int {
+			if compareTasks(task, pivot, q.Sort) <= 0 {
+				return -1
+			}
+			return 1
+		})
+	}
+	end := len(matched)
+	if q.Limit > 0 && start+q.Limit < end {
+		end = start + q.Limit
+		page.NextCursor = encodeCursor(matched[end-1], q.Sort)
+	}
+	page.Tasks = matched[start:end]
+	return page, nil
+}
+
+// matches reports whether task passes every filter of q
+func (q TaskQuery) matches(task Task) bool {
+	if q.Done != nil && task.Done != *q.Done {
+		return false
+	}
+	for _, tag := range normalizeTags(q.Tags) {
+		if !task.HasTag(tag) {
+			return false
+		}
+	}
+	if len(q.Priorities) > 0 && !slices.Contains(q.Priorities, task.Priority) {
+		return false
+	}
+	if q.DueBefore != nil && (task.DueAt == nil || !task.DueAt.Before(*q.DueBefore)) {
+		return false
+	}
+	if q.DueAfter != nil && (task.DueAt == nil || !task.DueAt.After(*q.DueAfter)) {
+		return false
+	}
+	if q.Search != "" {
+		search := strings.ToLower(q.Search)
+		if !strings.Contains(strings.ToLower(task.Title), search) &&
+			!strings.Contains(strings.ToLower(task.Description), search) {
+			return false
+		}
+	}
+	return true
+}
+
+// compareTasks orders a and b by keys and then by ID, tasks without a due date sort last in both directions
+func compareTasks(a, b Task, keys []SortKey) int {
+	for _, key := range keys {
+		var c int
+		switch key.Field {
+		case SortByID:
+			c = cmp.Compare(a.ID, b.ID)
+		case SortByTitle:
+			c = cmp.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+		case SortByPriority:
+			c = cmp.Compare(a.Priority, b.Priority)
+		case SortByDueAt:
+			switch {
+			case a.DueAt == nil && b.DueAt == nil:
+			case a.DueAt == nil:
+				return 1
+			case b.DueAt == nil:
+				return -1
+			default:
+				c = a.DueAt.Compare(*b.DueAt)
+			}
+		case SortByCreatedAt:
+			c = a.CreatedAt.Compare(b.CreatedAt)
+		case SortByUpdatedAt:
+			c = a.UpdatedAt.Compare(b.UpdatedAt)
+		}
+		if key.Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(a.ID, b.ID)
+}
+
+// cursor holds the sort values of the last task of a page, so the next page
+// starts at the right place even if that task changed or was deleted meanwhile
+type cursor struct {
+	Sort      string     `json:"s"`
+	ID        int        `json:"id"`
+	Title     string     `json:"t,omitempty"`
+	Priority  int        `json:"p,omitempty"`
+	DueAt     *time.Time `json:"d,omitempty"`
+	CreatedAt time.Time  `json:"c,omitzero"`
+	UpdatedAt time.Time  `json:"u,omitzero"`
+}
+
+// sortSpec renders keys in ParseSort syntax, it identifies the order a cursor belongs to
+func sortSpec(keys []SortKey) string {
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = string(key.Field)
+		if key.Desc {
+			parts[i] = "-" + parts[i]
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func encodeCursor(task Task, keys []SortKey) string {
+	c := cursor{Sort: sortSpec(keys), ID: task.ID}
+	for _, key := range keys {
+		switch key.Field {
+		case SortByTitle:
+			c.Title = task.Title
+		case SortByPriority:
+			c.Priority = int(task.Priority)
+		case SortByDueAt:
+			c.DueAt = task.DueAt
+		case SortByCreatedAt:
+			c.CreatedAt = task.CreatedAt
+		case SortByUpdatedAt:
+			c.UpdatedAt = task.UpdatedAt
+		}
+	}
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor returns a task carrying the sort values stored in s
+func decodeCursor(s string, keys []SortKey) (Task, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Task{}, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return Task{}, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+	if c.Sort != sortSpec(keys) {
+		return Task{}, fmt.Errorf("%w: cursor was issued for a different sort order", ErrInvalidQuery)
+	}
+	return Task{
+		ID:        c.ID,
+		Title:     c.Title,
+		Priority:  Priority(c.Priority),
+		DueAt:     c.DueAt,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	}, nil
+}
@@ -0,0 +1,183 @@
+package taskmanager
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// seedQueryTasks adds a fixed set of tasks and returns the manager
+func seedQueryTasks(t *testing.T) *TaskManager {
+	t.Helper()
+	tm := newClockedManager()
+	day := func(d int) time.Time { return time.Date(2025, 7, d, 12, 0, 0, 0, time.UTC) }
+
+	tasks := []struct {
+		title, desc string
+		opts        []TaskOption
+	}{
+		{"Write report", "Quarterly numbers", []TaskOption{WithPriority(PriorityHigh), WithTags("work"), WithDueAt(day(10))}},
+		{"Buy milk", "", []TaskOption{WithPriority(PriorityLow), WithTags("home")}},
+		{"Fix bug", "Crash in REPORT export", []TaskOption{WithPriority(PriorityUrgent), WithTags("work", "code"), WithDueAt(day(5))}},
+		{"call mom", "", []TaskOption{WithTags("home"), WithDueAt(day(20))}},
+		{"Review PR", "", []TaskOption{WithPriority(PriorityHigh), WithTags("work", "code"), WithDueAt(day(5))}},
+	}
+	for _, task := range tasks {
+		if _, err := tm.AddTask(task.title, task.desc, task.opts...); err != nil {
+			t.Fatalf("AddTask() failed: %v", err)
+		}
+	}
+	tm.UpdateTask(2, TaskUpdate{Done: ptr(true)})
+	return tm
+}
+
+func ids(tasks []Task) []int {
+	result := []int{}
+	for _, task := range tasks {
+		result = append(result, task.ID)
+	}
+	return result
+}
+
+func TestListTasksFilters(t *testing.T) {
+	tm := seedQueryTasks(t)
+	july := func(d int) *time.Time {
+		at := time.Date(2025, 7, d, 12, 0, 0, 0, time.UTC)
+		return &at
+	}
+
+	tests := []struct {
+		name  string
+		query TaskQuery
+		want  []int
+	}{
+		{"no filter", TaskQuery{}, []int{1, 2, 3, 4, 5}},
+		{"open", TaskQuery{Done: ptr(false)}, []int{1, 3, 4, 5}},
+		{"tag", TaskQuery{Tags: []string{"home"}}, []int{2, 4}},
+		{"all tags", TaskQuery{Tags: []string{"Code", "work"}}, []int{3, 5}},
+		{"priorities", TaskQuery{Priorities: []Priority{PriorityUrgent, PriorityLow}}, []int{2, 3}},
+		{"due before", TaskQuery{DueBefore: july(10)}, []int{3, 5}},
+		{"due after", TaskQuery{DueAfter: july(5)}, []int{1, 4}},
+		{"due between", TaskQuery{DueAfter: july(4), DueBefore: july(11)}, []int{1, 3, 5}},
+		{"search title and description", TaskQuery{Search: "report"}, []int{1, 3}},
+		{"combined", TaskQuery{Tags: []string{"work"}, Priorities: []Priority{PriorityHigh}, Search: "pr"}, []int{5}},
+		{"no match", TaskQuery{Search: "nothing"}, []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := tm.ListTasks(tt.query)
+			if err != nil {
+				t.Fatalf("ListTasks() failed: %v", err)
+			}
+			if got := ids(page.Tasks); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected tasks %v, got %v", tt.want, got)
+			}
+			if page.Total != len(tt.want) || page.NextCursor != "" {
+				t.Errorf("Expected total %d and no cursor, got %d and %q", len(tt.want), page.Total, page.NextCursor)
+			}
+		})
+	}
+}
+
+func TestListTasksSort(t *testing.T) {
+	tm := seedQueryTasks(t)
+
+	tests := []struct {
+		sort string
+		want []int
+	}{
+		{"", []int{1, 2, 3, 4, 5}},
+		{"-id", []int{5, 4, 3, 2, 1}},
+		{"title", []int{2, 4, 3, 5, 1}},
+		{"-priority", []int{3, 1, 5, 2, 4}},
+		{"-priority,-id", []int{3, 5, 1, 2, 4}},
+		{"due_at,-priority", []int{3, 5, 1, 4, 2}},
+		{"-due_at", []int{4, 1, 3, 5, 2}},
+		{"-updated_at", []int{2, 5, 4, 3, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			keys, err := ParseSort(tt.sort)
+			if err != nil {
+				t.Fatalf("ParseSort() failed: %v", err)
+			}
+			page, err := tm.ListTasks(TaskQuery{Sort: keys})
+			if err != nil {
+				t.Fatalf("ListTasks() failed: %v", err)
+			}
+			if got := ids(page.Tasks); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected order %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestListTasksPagination(t *testing.T) {
+	tm := seedQueryTasks(t)
+	keys, _ := ParseSort("due_at,-priority")
+
+	var got []int
+	query := TaskQuery{Sort: keys, Limit: 2}
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("Pagination did not terminate")
+		}
+		page, err := tm.ListTasks(query)
+		if err != nil {
+			t.Fatalf("ListTasks() failed: %v", err)
+		}
+		if page.Total != 5 {
+			t.Errorf("Expected total 5 on every page, got %d", page.Total)
+		}
+		got = append(got, ids(page.Tasks)...)
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+	if want := []int{3, 5, 1, 4, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected pages to cover %v, got %v", want, got)
+	}
+
+	// A cursor stays valid when the last task of its page is deleted
+	first, _ := tm.ListTasks(TaskQuery{Sort: keys, Limit: 2})
+	if err := tm.DeleteTask(5); err != nil {
+		t.Fatalf("DeleteTask() failed: %v", err)
+	}
+	next, err := tm.ListTasks(TaskQuery{Sort: keys, Limit: 2, Cursor: first.NextCursor})
+	if err != nil {
+		t.Fatalf("ListTasks() failed: %v", err)
+	}
+	if got := ids(next.Tasks); !reflect.DeepEqual(got, []int{1, 4}) {
+		t.Errorf("Expected the page after a deleted task to be [1 4], got %v", got)
+	}
+}
+
+func TestListTasksInvalidQuery(t *testing.T) {
+	tm := seedQueryTasks(t)
+	page, _ := tm.ListTasks(TaskQuery{Limit: 1})
+
+	tests := []struct {
+		name  string
+		query TaskQuery
+	}{
+		{"negative limit", TaskQuery{Limit: -1}},
+		{"unknown field", TaskQuery{Sort: []SortKey{{Field: "color"}}}},
+		{"malformed cursor", TaskQuery{Cursor: "!!!"}},
+		{"cursor for another sort", TaskQuery{Sort: []SortKey{{Field: SortByTitle}}, Cursor: page.NextCursor}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tm.ListTasks(tt.query); !errors.Is(err, ErrInvalidQuery) {
+				t.Errorf("Expected ErrInvalidQuery, got %v", err)
+			}
+		})
+	}
+
+	if _, err := ParseSort("priority,-size"); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("Expected ErrInvalidQuery from ParseSort, got %v", err)
+	}
+}
@@ -0,0 +1,217 @@
+package taskmanager
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidRecurrence is returned for a recurrence rule that cannot be parsed or never fires
+var ErrInvalidRecurrence = errors.New("invalid recurrence")
+
+// cronHorizon bounds the search for the next cron occurrence
+const cronHorizon = 5 * 366 * 24 * time.Hour
+
+// Recurrence computes the occurrences of a recurring task
+type Recurrence interface {
+	// Next returns the first occurrence strictly after t, or the zero time if there is none
+	Next(t time.Time) time.Time
+	// String returns the rule in the syntax accepted by ParseRecurrence
+	String() string
+}
+
+// ParseRecurrence parses a recurrence rule. Supported rules are "daily", "weekly",
+// "every N days", "every N weeks" and five-field cron expressions such as
+// "30 9 * * 1-5" (minute, hour, day of month, month, day of week).
+func ParseRecurrence(s string) (Recurrence, error) {
+	fields := strings.Fields(strings.ToLower(s))
+	switch {
+	case len(fields) == 1 && fields[0] == "daily":
+		return intervalRule{days: 1}, nil
+	case len(fields) == 1 && fields[0] == "weekly":
+		return intervalRule{days: 7}, nil
+	case len(fields) == 3 && fields[0] == "every":
+		n, err := strconv.Atoi(fields[1])
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("%w: %q: count must be a positive number", ErrInvalidRecurrence, s)
+		}
+		switch strings.TrimSuffix(fields[2], "s") {
+		case "day":
+			return intervalRule{days: n}, nil
+		case "week":
+			return intervalRule{days: 7 * n}, nil
+		}
+		return nil, fmt.Errorf("%w: %q: unit must be days or weeks", ErrInvalidRecurrence, s)
+	case len(fields) == 5:
+		return parseCron(s, fields)
+	}
+	return nil, fmt.Errorf("%w: %q", ErrInvalidRecurrence, s)
+}
+
+// intervalRule repeats every fixed number of calendar days, keeping the time of day
+type intervalRule struct {
+	days int
+}
+
+func (r intervalRule) Next(t time.Time) time.Time {
+	return t.AddDate(0, 0, r.days)
+}
+
+func (r intervalRule) String() string {
+	switch {
+	case r.days == 1:
+		return "daily"
+	case r.days == 7:
+		return "weekly"
+	case r.days%7 == 0:
+		return fmt.Sprintf("every %d weeks", r.days/7)
+	}
+	return fmt.Sprintf("every %d days", r.days)
+}
+
+// cronRule matches minutes, hours, days, months and weekdays against bit sets.
+// As in cron, a day matches either field when both day of month and day of week are restricted.
+type cronRule struct {
+	spec                          string
+	minute, hour, dom, month, dow uint64
+	domAny, dowAny                bool
+}
+
+// cronFields lists the name and range of each cron field, day of week 7 is Sunday like 0
+var cronFields = []struct {
+	name     string
+	min, max int
+}{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+func parseCron(s string, fields []string) (Recurrence, error) {
+	sets := make([]uint64, len(fields))
+	for i, field := range fields {
+		set, err := parseCronField(field, cronFields[i].min, cronFields[i].max)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: %s: %v", ErrInvalidRecurrence, s, cronFields[i].name, err)
+		}
+		sets[i] = set
+	}
+	if sets[4]&(1<<7) != 0 {
+		sets[4] |= 1
+	}
+
+	rule := cronRule{
+		spec:   strings.Join(fields, " "),
+		minute: sets[0], hour: sets[1], dom: sets[2], month: sets[3], dow: sets[4],
+		domAny: strings.HasPrefix(fields[2], "*"), dowAny: strings.HasPrefix(fields[4], "*"),
+	}
+	if rule.Next(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)).IsZero() {
+		return nil, fmt.Errorf("%w: %q never fires", ErrInvalidRecurrence, s)
+	}
+	return rule, nil
+}
+
+// parseCronField parses a comma separated list of "*", "N", "A-B" with an optional "/STEP"
+func parseCronField(field string, min, max int) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step %q", stepPart)
+			}
+			step = n
+		}
+
+		lo, hi := min, max
+		if rangePart != "*" {
+			from, to, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = strconv.Atoi(from); err != nil {
+				return 0, fmt.Errorf("invalid value %q", from)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(to); err != nil {
+					return 0, fmt.Errorf("invalid value %q", to)
+				}
+			} else if hasStep {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("%q is out of range %d-%d", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+func (r cronRule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	limit := t.Add(cronHorizon)
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, loc).Add(time.Minute)
+
+	for t.Before(limit) {
+		year, month, day := t.Date()
+		switch {
+		case r.month&(1<<month) == 0:
+			t = time.Date(year, month+1, 1, 0, 0, 0, 0, loc)
+		case !r.matchDay(t):
+			t = time.Date(year, month, day+1, 0, 0, 0, 0, loc)
+		case r.hour&(1<<t.Hour()) == 0:
+			t = time.Date(year, month, day, t.Hour()+1, 0, 0, 0, loc)
+		case r.minute&(1<<t.Minute()) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (r cronRule) matchDay(t time.Time) bool {
+	dom := r.dom&(1<<t.Day()) != 0
+	dow := r.dow&(1<<t.Weekday()) != 0
+	if r.domAny || r.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+func (r cronRule) String() string {
+	return r.spec
+}
+
+// nextOccurrence returns the due date of the occurrence that follows task,
+// skipping occurrences that are already in the past at now
+func nextOccurrence(task Task, rule Recurrence, now time.Time) time.Time {
+	next := rule.Next(now)
+	if task.DueAt != nil {
+		next = rule.Next(*task.DueAt)
+		for !next.IsZero() && !next.After(now) {
+			next = rule.Next(next)
+		}
+	}
+	return next
+}
+
+// validRecurrence normalizes a rule for storage, the empty rule means the task does not repeat
+func validRecurrence(rule string) (string, error) {
+	if strings.TrimSpace(rule) == "" {
+		return "", nil
+	}
+	parsed, err := ParseRecurrence(rule)
+	if err != nil {
+		return "", err
+	}
+	return parsed.String(), nil
+}
@@ -0,0 +1,244 @@
+package taskmanager
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	// Wednesday
+	from := time.Date(2025, 7, 2, 10, 15, 0, 0, time.UTC)
+
+	tests := []struct {
+		rule   string
+		normal string
+		next   time.Time
+	}{
+		{"daily", "daily", time.Date(2025, 7, 3, 10, 15, 0, 0, time.UTC)},
+		{"Weekly", "weekly", time.Date(2025, 7, 9, 10, 15, 0, 0, time.UTC)},
+		{"every 3 days", "every 3 days", time.Date(2025, 7, 5, 10, 15, 0, 0, time.UTC)},
+		{"every 1 day", "daily", time.Date(2025, 7, 3, 10, 15, 0, 0, time.UTC)},
+		{"every 2 weeks", "every 2 weeks", time.Date(2025, 7, 16, 10, 15, 0, 0, time.UTC)},
+		{"every 14 days", "every 2 weeks", time.Date(2025, 7, 16, 10, 15, 0, 0, time.UTC)},
+		{"30 9 * * *", "30 9 * * *", time.Date(2025, 7, 3, 9, 30, 0, 0, time.UTC)},
+		{"*/20  *  * * *", "*/20 * * * *", time.Date(2025, 7, 2, 10, 20, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", "0 9 * * 1-5", time.Date(2025, 7, 3, 9, 0, 0, 0, time.UTC)},
+		{"0 9 * * 0", "0 9 * * 0", time.Date(2025, 7, 6, 9, 0, 0, 0, time.UTC)},
+		{"0 9 * * 7", "0 9 * * 7", time.Date(2025, 7, 6, 9, 0, 0, 0, time.UTC)},
+		{"0 0 1 */3 *", "0 0 1 */3 *", time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", "0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// Day of month or day of week when both are restricted
+		{"0 12 15 * 5", "0 12 15 * 5", time.Date(2025, 7, 4, 12, 0, 0, 0, time.UTC)},
+		{"15,45 8-10 * * *", "15,45 8-10 * * *", time.Date(2025, 7, 2, 10, 45, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			rule, err := ParseRecurrence(tt.rule)
+			if err != nil {
+				t.Fatalf("ParseRecurrence() failed: %v", err)
+			}
+			if rule.String() != tt.normal {
+				t.Errorf("Expected rule %q, got %q", tt.normal, rule.String())
+			}
+			if next := rule.Next(from); !next.Equal(tt.next) {
+				t.Errorf("Expected next occurrence %v, got %v", tt.next, next)
+			}
+		})
+	}
+}
+
+func TestParseRecurrenceInvalid(t *testing.T) {
+	rules := []string{
+		"", "hourly", "every day", "every 0 days", "every 2 months",
+		"* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8",
+		"5-1 * * * *", "*/0 * * * *", "a * * * *", "0 0 30 2 *",
+	}
+	for _, rule := range rules {
+		t.Run(rule, func(t *testing.T) {
+			if _, err := ParseRecurrence(rule); !errors.Is(err, ErrInvalidRecurrence) {
+				t.Errorf("Expected ErrInvalidRecurrence, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRecurringTaskSpawnsNextOccurrence(t *testing.T) {
+	tm := NewTaskManager()
+	now := time.Date(2025, 7, 2, 8, 0, 0, 0, time.UTC)
+	tm.now = func() time.Time { return now }
+
+	due := time.Date(2025, 7, 2, 9, 0, 0, 0, time.UTC)
+	task, err := tm.AddTask("Standup", "Team sync", WithRecurrence(" Daily "), WithDueAt(due),
+		WithPriority(PriorityHigh), WithTags("work"))
+	if err != nil {
+		t.Fatalf("AddTask() failed: %v", err)
+	}
+	if task.Recurrence != "daily" {
+		t.Errorf("Expected normalized rule, got %q", task.Recurrence)
+	}
+
+	done, err := tm.UpdateTask(task.ID, TaskUpdate{Done: ptr(true)})
+	if err != nil {
+		t.Fatalf("UpdateTask() failed: %v", err)
+	}
+	if done.Recurrence != "" {
+		t.Errorf("Completed occurrence must hand the rule over, got %q", done.Recurrence)
+	}
+
+	open, _ := tm.ListTasks(TaskQuery{Done: ptr(false)})
+	if len(open.Tasks) != 1 {
+		t.Fatalf("Expected one next occurrence, got %+v", open.Tasks)
+	}
+	next := open.Tasks[0]
+	if next.Title != "Standup" || next.Description != "Team sync" || next.Priority != PriorityHigh ||
+		!next.HasTag("work") || next.Recurrence != "daily" {
+		t.Errorf("Next occurrence must copy the task, got %+v", next)
+	}
+	if want := due.AddDate(0, 0, 1); next.DueAt == nil || !next.DueAt.Equal(want) {
+		t.Errorf("Expected next due date %v, got %v", want, next.DueAt)
+	}
+
+	// Reopening and completing again must not spawn a second occurrence
+	tm.UpdateTask(task.ID, TaskUpdate{Done: ptr(false)})
+	tm.UpdateTask(task.ID, TaskUpdate{Done: ptr(true)})
+	if page, _ := tm.ListTasks(TaskQuery{}); page.Total != 2 {
+		t.Errorf("Expected 2 tasks, got %d", page.Total)
+	}
+
+	// Occurrences missed while the task was overdue are skipped
+	now = time.Date(2025, 7, 10, 12, 0, 0, 0, time.UTC)
+	tm.UpdateTask(next.ID, TaskUpdate{Done: ptr(true)})
+	open, _ = tm.ListTasks(TaskQuery{Done: ptr(false)})
+	if want := time.Date(2025, 7, 11, 9, 0, 0, 0, time.UTC); len(open.Tasks) != 1 || !open.Tasks[0].DueAt.Equal(want) {
+		t.Errorf("Expected one occurrence due %v, got %+v", want, open.Tasks)
+	}
+
+	// Clearing the rule stops the series
+	last := open.Tasks[0]
+	tm.UpdateTask(last.ID, TaskUpdate{Recurrence: ptr("")})
+	tm.UpdateTask(last.ID, TaskUpdate{Done: ptr(true)})
+	if open, _ = tm.ListTasks(TaskQuery{Done: ptr(false)}); open.Total != 0 {
+		t.Errorf("Expected no open tasks after the series stopped, got %+v", open.Tasks)
+	}
+}
+
+func TestRecurringTaskWithoutDueDate(t *testing.T) {
+	tm := NewTaskManager()
+	now := time.Date(2025, 7, 2, 8, 10, 0, 0, time.UTC)
+	tm.now = func() time.Time { return now }
+
+	task, _ := tm.AddTask("Water plants", "", WithRecurrence("0 18 * * *"))
+	tm.UpdateTask(task.ID, TaskUpdate{Done: ptr(true)})
+
+	open, _ := tm.ListTasks(TaskQuery{Done: ptr(false)})
+	if want := time.Date(2025, 7, 2, 18, 0, 0, 0, time.UTC); len(open.Tasks) != 1 || !open.Tasks[0].DueAt.Equal(want) {
+		t.Errorf("Expected one occurrence due %v, got %+v", want, open.Tasks)
+	}
+
+	if _, err := tm.AddTask("Bad", "", WithRecurrence("sometimes")); !errors.Is(err, ErrInvalidRecurrence) {
+		t.Errorf("Expected ErrInvalidRecurrence, got %v", err)
+	}
+	if _, err := tm.UpdateTask(task.ID, TaskUpdate{Recurrence: ptr("every -1 days")}); !errors.Is(err, ErrInvalidRecurrence) {
+		t.Errorf("Expected ErrInvalidRecurrence, got %v", err)
+	}
+}
+
+func TestRecurringSubtaskCompletedByParent(t *testing.T) {
+	tm := newClockedManager()
+	project, _ := tm.AddTask("Sprint", "")
+	standup, _ := tm.AddTask("Standup", "", WithParent(project.ID), WithRecurrence("daily"))
+
+	if _, err := tm.UpdateTask(project.ID, TaskUpdate{Done: ptr(true)}); err != nil {
+		t.Fatalf("UpdateTask() failed: %v", err)
+	}
+
+	done, _ := tm.GetTask(standup.ID)
+	if !done.Done || done.Recurrence != "" {
+		t.Errorf("Expected the subtask to be completed and hand over its rule, got %+v", done)
+	}
+	open, _ := tm.ListTasks(TaskQuery{Done: ptr(false)})
+	if len(open.Tasks) != 1 || open.Tasks[0].Title != "Standup" || open.Tasks[0].ParentID != project.ID ||
+		open.Tasks[0].Recurrence != "daily" {
+		t.Errorf("Expected the next occurrence under the same parent, got %+v", open.Tasks)
+	}
+}
+
+func TestRecurringParentCompletedByRollUp(t *testing.T) {
+	tm := newClockedManager()
+	goal, _ := tm.AddTask("Quarter", "")
+	review, _ := tm.AddTask("Weekly review", "", WithParent(goal.ID), WithRecurrence("weekly"))
+	step, _ := tm.AddTask("Collect notes", "", WithParent(review.ID))
+
+	if _, err := tm.UpdateTask(step.ID, TaskUpdate{Done: ptr(true)}); err != nil {
+		t.Fatalf("UpdateTask() failed: %v", err)
+	}
+
+	done, _ := tm.GetTask(review.ID)
+	if !done.Done || done.Recurrence != "" {
+		t.Errorf("Expected the parent to roll up and hand over its rule, got %+v", done)
+	}
+	open, _ := tm.ListTasks(TaskQuery{Done: ptr(false)})
+	if len(open.Tasks) != 2 || open.Tasks[0].ID != goal.ID || open.Tasks[1].Title != "Weekly review" ||
+		open.Tasks[1].Recurrence != "weekly" || open.Tasks[1].ParentID != goal.ID {
+		t.Errorf("Expected the next occurrence to keep the grandparent open, got %+v", open.Tasks)
+	}
+}
+
+// failingCreateStore rejects new tasks once fail is set
+type failingCreateStore struct {
+	TaskStore
+	fail bool
+}
+
+func (s *failingCreateStore) Create(task Task) (Task, error) {
+	if s.fail {
+		return Task{}, errors.New("disk full")
+	}
+	return s.TaskStore.Create(task)
+}
+
+func TestRecurringCompletionIsAtomic(t *testing.T) {
+	store := &failingCreateStore{TaskStore: NewMemoryStore()}
+	tm := NewTaskManagerWithStore(store)
+	task, _ := tm.AddTask("Standup", "", WithRecurrence("daily"))
+
+	store.fail = true
+	if _, err := tm.UpdateTask(task.ID, TaskUpdate{Done: ptr(true)}); err == nil {
+		t.Fatal("Expected the failed occurrence to fail the update")
+	}
+	got, _ := tm.GetTask(task.ID)
+	if got.Done || got.Recurrence != "daily" {
+		t.Errorf("Expected the task to keep its state and rule, got %+v", got)
+	}
+
+	store.fail = false
+	if _, err := tm.UpdateTask(task.ID, TaskUpdate{Done: ptr(true)}); err != nil {
+		t.Fatalf("UpdateTask() failed: %v", err)
+	}
+	if page, _ := tm.ListTasks(TaskQuery{}); page.Total != 2 {
+		t.Errorf("Expected the retry to add the next occurrence, got %d tasks", page.Total)
+	}
+}
+
+func TestStoresPersistRecurrence(t *testing.T) {
+	for name := range storeFactories {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "tasks")
+			store := openStore(t, name, path)
+			task, err := store.Create(Task{Title: "Standup", Recurrence: "0 9 * * 1-5"})
+			if err != nil {
+				t.Fatalf("Create() failed: %v", err)
+			}
+			got, err := store.Get(task.ID)
+			if err != nil {
+				t.Fatalf("Get() failed: %v", err)
+			}
+			if got.Recurrence != "0 9 * * 1-5" {
+				t.Errorf("Expected recurrence to round-trip, got %q", got.Recurrence)
+			}
+		})
+	}
+}
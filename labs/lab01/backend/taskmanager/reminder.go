@@ -0,0 +1,172 @@
+package taskmanager
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Clock tells the time and waits, the reminder scheduler uses it so tests can control time
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// ReminderKind tells why a reminder was sent
+type ReminderKind int
+
+const (
+	// ReminderDueSoon is sent once when an open task comes within the lead time of its due date
+	ReminderDueSoon ReminderKind = iota
+	// ReminderOverdue is sent once when the due date of an open task has passed
+	ReminderOverdue
+)
+
+func (k ReminderKind) String() string {
+	switch k {
+	case ReminderDueSoon:
+		return "due soon"
+	case ReminderOverdue:
+		return "overdue"
+	}
+	return fmt.Sprintf("ReminderKind(%d)", int(k))
+}
+
+// Reminder is an event about a task with a due date
+type Reminder struct {
+	Kind ReminderKind
+	Task Task
+	// At is the time the scheduler noticed the task
+	At time.Time
+}
+
+// reminderKey identifies a sent reminder, a new due date makes a new key
+type reminderKey struct {
+	id   int
+	kind ReminderKind
+	due  int64
+}
+
+// ReminderScheduler watches the open tasks of a TaskManager and emits due-soon and overdue reminders
+type ReminderScheduler struct {
+	tm       *TaskManager
+	clock    Clock
+	lead     time.Duration
+	interval time.Duration
+	events   chan Reminder
+
+	mu   sync.Mutex
+	sent map[reminderKey]bool
+}
+
+// SchedulerOption configures a ReminderScheduler
+type SchedulerOption func(s *ReminderScheduler)
+
+// WithClock replaces the system clock
+func WithClock(clock Clock) SchedulerOption {
+	return func(s *ReminderScheduler) {
+		s.clock = clock
+	}
+}
+
+// WithLeadTime sets how long before the due date the due-soon reminder is sent, one hour by default
+func WithLeadTime(lead time.Duration) SchedulerOption {
+	return func(s *ReminderScheduler) {
+		s.lead = lead
+	}
+}
+
+// WithCheckInterval sets how often Run looks at the tasks, once a minute by default
+func WithCheckInterval(interval time.Duration) SchedulerOption {
+	return func(s *ReminderScheduler) {
+		s.interval = interval
+	}
+}
+
+// NewReminderScheduler creates a scheduler for the tasks of tm, Run starts it
+func NewReminderScheduler(tm *TaskManager, opts ...SchedulerOption) *ReminderScheduler {
+	s := &ReminderScheduler{
+		tm:       tm,
+		clock:    systemClock{},
+		lead:     time.Hour,
+		interval: time.Minute,
+		events:   make(chan Reminder),
+		sent:     make(map[reminderKey]bool),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Events returns the channel reminders are sent on, it is closed when Run returns
+func (s *ReminderScheduler) Events() <-chan Reminder {
+	return s.events
+}
+
+// Run checks the tasks every interval and sends new reminders on Events until ctx is done.
+// It is meant to run in its own goroutine and returns ctx.Err() or the error of a failed check.
+func (s *ReminderScheduler) Run(ctx context.Context) error {
+	defer close(s.events)
+	for {
+		reminders, err := s.Check()
+		if err != nil {
+			return err
+		}
+		for _, reminder := range reminders {
+			select {
+			case s.events <- reminder:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		select {
+		case <-s.clock.After(s.interval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Check returns the reminders that are due now and were not returned before, ordered by due date.
+// Run calls it on every tick, calling it directly allows checking without the goroutine.
+func (s *ReminderScheduler) Check() ([]Reminder, error) {
+	now := s.clock.Now()
+	horizon := now.Add(s.lead)
+	open := false
+	page, err := s.tm.ListTasks(TaskQuery{
+		Done:      &open,
+		DueBefore: &horizon,
+		Sort:      []SortKey{{Field: SortByDueAt}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Keys of tasks that left the window are dropped, so a task that comes back gets reminded again
+	sent := make(map[reminderKey]bool, len(page.Tasks))
+	reminders := []Reminder{}
+	for _, task := range page.Tasks {
+		kind := ReminderDueSoon
+		if !task.DueAt.After(now) {
+			kind = ReminderOverdue
+		}
+		key := reminderKey{id: task.ID, kind: kind, due: task.DueAt.UnixNano()}
+		if !s.sent[key] {
+			reminders = append(reminders, Reminder{Kind: kind, Task: task, At: now})
+		}
+		sent[key] = true
+	}
+	s.sent = sent
+	return reminders, nil
+}
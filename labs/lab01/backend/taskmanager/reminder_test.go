@@ -0,0 +1,153 @@
+package taskmanager
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock only moves when Advance is called, waiting reports every call to After
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	timers  []fakeTimer
+	waiting chan struct{}
+}
+
+type fakeTimer struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{now: now, waiting: make(chan struct{}, 16)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	c.timers = append(c.timers, fakeTimer{at: c.now.Add(d), ch: ch})
+	c.waiting <- struct{}{}
+	return ch
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	pending := c.timers[:0]
+	for _, timer := range c.timers {
+		if timer.at.After(c.now) {
+			pending = append(pending, timer)
+			continue
+		}
+		timer.ch <- c.now
+	}
+	c.timers = pending
+}
+
+func TestReminderSchedulerCheck(t *testing.T) {
+	start := time.Date(2025, 7, 2, 9, 0, 0, 0, time.UTC)
+	clock := newFakeClock(start)
+	tm := NewTaskManager()
+	soon, _ := tm.AddTask("Soon", "", WithDueAt(start.Add(30*time.Minute)))
+	late, _ := tm.AddTask("Late", "", WithDueAt(start.Add(-time.Hour)))
+	later, _ := tm.AddTask("Later", "", WithDueAt(start.Add(3*time.Hour)))
+	done, _ := tm.AddTask("Done", "", WithDueAt(start.Add(-time.Hour)))
+	tm.AddTask("No due date", "")
+	tm.UpdateTask(done.ID, TaskUpdate{Done: ptr(true)})
+
+	s := NewReminderScheduler(tm, WithClock(clock), WithLeadTime(time.Hour))
+
+	type event struct {
+		id   int
+		kind ReminderKind
+	}
+	check := func(want ...event) {
+		t.Helper()
+		reminders, err := s.Check()
+		if err != nil {
+			t.Fatalf("Check() failed: %v", err)
+		}
+		got := []event{}
+		for _, r := range reminders {
+			got = append(got, event{r.Task.ID, r.Kind})
+			if !r.At.Equal(clock.Now()) {
+				t.Errorf("Expected reminder time %v, got %v", clock.Now(), r.At)
+			}
+		}
+		if len(want) == 0 {
+			want = []event{}
+		}
+		if len(got) != len(want) {
+			t.Fatalf("Expected reminders %v, got %v", want, got)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("Expected reminders %v, got %v", want, got)
+			}
+		}
+	}
+
+	check(event{late.ID, ReminderOverdue}, event{soon.ID, ReminderDueSoon})
+	check()
+
+	clock.Advance(time.Hour)
+	check(event{soon.ID, ReminderOverdue})
+
+	clock.Advance(time.Hour + time.Minute)
+	check(event{later.ID, ReminderDueSoon})
+
+	// A new due date is a new reminder, completing a task silences it
+	tm.UpdateTask(late.ID, TaskUpdate{DueAt: ptr(clock.Now().Add(10 * time.Minute))})
+	tm.UpdateTask(soon.ID, TaskUpdate{Done: ptr(true)})
+	check(event{late.ID, ReminderDueSoon})
+
+	clock.Advance(time.Hour)
+	check(event{late.ID, ReminderOverdue}, event{later.ID, ReminderOverdue})
+}
+
+func TestReminderSchedulerRun(t *testing.T) {
+	start := time.Date(2025, 7, 2, 9, 0, 0, 0, time.UTC)
+	clock := newFakeClock(start)
+	tm := NewTaskManager()
+	task, _ := tm.AddTask("Report", "", WithDueAt(start.Add(90*time.Minute)))
+
+	s := NewReminderScheduler(tm, WithClock(clock), WithLeadTime(time.Hour), WithCheckInterval(time.Minute))
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() { result <- s.Run(ctx) }()
+
+	// Nothing is due yet, the first check ends waiting for the next tick
+	<-clock.waiting
+	clock.Advance(45 * time.Minute)
+	reminder := <-s.Events()
+	if reminder.Kind != ReminderDueSoon || reminder.Task.ID != task.ID {
+		t.Errorf("Expected due-soon reminder for task %d, got %+v", task.ID, reminder)
+	}
+
+	<-clock.waiting
+	clock.Advance(time.Hour)
+	reminder = <-s.Events()
+	if reminder.Kind != ReminderOverdue || reminder.Task.ID != task.ID {
+		t.Errorf("Expected overdue reminder for task %d, got %+v", task.ID, reminder)
+	}
+
+	<-clock.waiting
+	cancel()
+	if err := <-result; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if _, ok := <-s.Events(); ok {
+		t.Error("Expected Events to be closed after Run returns")
+	}
+}
@@ -0,0 +1,221 @@
+package taskmanager
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqliteMigrations are applied in order, PRAGMA user_version records how many ran
+var sqliteMigrations = []string{
+	// AUTOINCREMENT keeps IDs of deleted tasks from being reused
+	`CREATE TABLE tasks (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		title TEXT NOT NULL,
+		description TEXT NOT NULL,
+		done BOOLEAN NOT NULL,
+		created_at TIMESTAMP NOT NULL
+	)`,
+	`ALTER TABLE tasks ADD COLUMN parent_id INTEGER NULL REFERENCES tasks(id);
+	ALTER TABLE tasks ADD COLUMN priority INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE tasks ADD COLUMN tags TEXT NOT NULL DEFAULT '[]';
+	ALTER TABLE tasks ADD COLUMN due_at TIMESTAMP NULL;
+	ALTER TABLE tasks ADD COLUMN updated_at TIMESTAMP NULL;
+	ALTER TABLE tasks ADD COLUMN completed_at TIMESTAMP NULL;
+	UPDATE tasks SET updated_at = created_at;
+	CREATE INDEX idx_tasks_parent_id ON tasks(parent_id);`,
+	`ALTER TABLE tasks ADD COLUMN recurrence TEXT NOT NULL DEFAULT ''`,
+}
+
+const taskColumns = `id, parent_id, title, description, done, priority, tags, due_at, recurrence, created_at, updated_at, completed_at`
+
+// SQLiteStore keeps tasks in a SQLite database file
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// OpenSQLiteStore opens or creates the database at path and brings its schema up to date
+func OpenSQLiteStore(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite3", path+"?_busy_timeout=5000&_txlock=immediate")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open task database: %w", err)
+	}
+	// One connection serializes writers and keeps :memory: databases alive
+	db.SetMaxOpenConns(1)
+
+	if err := migrateSQLite(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+func migrateSQLite(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	for i := version; i < len(sqliteMigrations); i++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return fmt.Errorf("failed to migrate task database: %w", err)
+		}
+		if _, err := tx.Exec(sqliteMigrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to apply migration %d: %w", i+1, err)
+		}
+		if _, err := tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, i+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to apply migration %d: %w", i+1, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to apply migration %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func (s *SQLiteStore) Create(task Task) (Task, error) {
+	tags, err := json.Marshal(tagsOrEmpty(task.Tags))
+	if err != nil {
+		return Task{}, fmt.Errorf("failed to encode tags: %w", err)
+	}
+	result, err := s.db.Exec(`
+		INSERT INTO tasks (parent_id, title, description, done, priority, tags, due_at, recurrence, created_at, updated_at, completed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		nullID(task.ParentID), task.Title, task.Description, task.Done, int(task.Priority), string(tags),
+		task.DueAt, task.Recurrence, task.CreatedAt, task.UpdatedAt, task.CompletedAt)
+	if err != nil {
+		return Task{}, fmt.Errorf("failed to create task: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return Task{}, fmt.Errorf("failed to create task: %w", err)
+	}
+	task.ID = int(id)
+	return task, nil
+}
+
+func (s *SQLiteStore) Get(id int) (Task, error) {
+	return scanTask(s.db.QueryRow(`SELECT `+taskColumns+` FROM tasks WHERE id = ?`, id))
+}
+
+func (s *SQLiteStore) Update(id int, fn func(task *Task) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to update task: %w", err)
+	}
+	defer tx.Rollback()
+
+	task, err := scanTask(tx.QueryRow(`SELECT `+taskColumns+` FROM tasks WHERE id = ?`, id))
+	if err != nil {
+		return err
+	}
+	if err := fn(&task); err != nil {
+		return err
+	}
+
+	tags, err := json.Marshal(tagsOrEmpty(task.Tags))
+	if err != nil {
+		return fmt.Errorf("failed to encode tags: %w", err)
+	}
+	_, err = tx.Exec(`
+		UPDATE tasks SET parent_id = ?, title = ?, description = ?, done = ?, priority = ?, tags = ?,
+			due_at = ?, recurrence = ?, created_at = ?, updated_at = ?, completed_at = ?
+		WHERE id = ?`,
+		nullID(task.ParentID), task.Title, task.Description, task.Done, int(task.Priority), string(tags),
+		task.DueAt, task.Recurrence, task.CreatedAt, task.UpdatedAt, task.CompletedAt, id)
+	if err != nil {
+		return fmt.Errorf("failed to update task: %w", err)
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) Delete(id int) error {
+	result, err := s.db.Exec(`DELETE FROM tasks WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete task: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete task: %w", err)
+	}
+	if n == 0 {
+		return ErrTaskNotFound
+	}
+	return nil
+}
+
+func (s *SQLiteStore) List() ([]Task, error) {
+	rows, err := s.db.Query(`SELECT ` + taskColumns + ` FROM tasks ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+	defer rows.Close()
+
+	result := []Task{}
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+	return result, nil
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// scanner is implemented by *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTask(row scanner) (Task, error) {
+	var (
+		task      Task
+		parentID  sql.NullInt64
+		priority  int
+		tags      string
+		updatedAt sql.NullTime
+	)
+	err := row.Scan(&task.ID, &parentID, &task.Title, &task.Description, &task.Done, &priority, &tags,
+		&task.DueAt, &task.Recurrence, &task.CreatedAt, &updatedAt, &task.CompletedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Task{}, ErrTaskNotFound
+	}
+	if err != nil {
+		return Task{}, fmt.Errorf("failed to read task: %w", err)
+	}
+
+	task.ParentID = int(parentID.Int64)
+	task.Priority = Priority(priority)
+	task.UpdatedAt = updatedAt.Time
+	if err := json.Unmarshal([]byte(tags), &task.Tags); err != nil {
+		return Task{}, fmt.Errorf("failed to decode tags of task %d: %w", task.ID, err)
+	}
+	if len(task.Tags) == 0 {
+		task.Tags = nil
+	}
+	return task, nil
+}
+
+// nullID stores the ParentID 0 of top-level tasks as NULL
+func nullID(id int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(id), Valid: id != 0}
+}
+
+func tagsOrEmpty(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
@@ -0,0 +1,20 @@
+package taskmanager
+
+// TaskStore persists tasks. Implementations are safe for concurrent use and
+// allocate IDs atomically: an ID is never handed out twice, not even after
+// the task was deleted or the store was reopened.
+type TaskStore interface {
+	// Create assigns the next ID to task, stores it and returns the stored task
+	Create(task Task) (Task, error)
+	// Get returns the task with id or ErrTaskNotFound
+	Get(id int) (Task, error)
+	// Update applies fn to the task with id and stores the result atomically.
+	// Nothing is stored when fn returns an error, which is passed through.
+	Update(id int, fn func(task *Task) error) error
+	// Delete removes the task with id or returns ErrTaskNotFound
+	Delete(id int) error
+	// List returns all tasks ordered by ID
+	List() ([]Task, error)
+	// Close releases the resources of the store
+	Close() error
+}
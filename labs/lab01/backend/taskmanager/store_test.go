@@ -0,0 +1,188 @@
+package taskmanager
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// storeFactory opens a store persisted at path, reopening the same path must see the same tasks
+type storeFactory func(t *testing.T, path string) TaskStore
+
+var storeFactories = map[string]storeFactory{
+	"memory": nil,
+	"json": func(t *testing.T, path string) TaskStore {
+		store, err := OpenJSONStore(path + ".json")
+		if err != nil {
+			t.Fatalf("OpenJSONStore() failed: %v", err)
+		}
+		return store
+	},
+	"sqlite": func(t *testing.T, path string) TaskStore {
+		store, err := OpenSQLiteStore(path + ".db")
+		if err != nil {
+			t.Fatalf("OpenSQLiteStore() failed: %v", err)
+		}
+		return store
+	},
+}
+
+func openStore(t *testing.T, name, path string) TaskStore {
+	t.Helper()
+	if storeFactories[name] == nil {
+		return NewMemoryStore()
+	}
+	store := storeFactories[name](t, path)
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestStores(t *testing.T) {
+	for name := range storeFactories {
+		t.Run(name, func(t *testing.T) {
+			store := openStore(t, name, filepath.Join(t.TempDir(), "tasks"))
+			created := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+
+			first, err := store.Create(Task{Title: "First", Description: "one", CreatedAt: created})
+			if err != nil {
+				t.Fatalf("Create() failed: %v", err)
+			}
+			second, err := store.Create(Task{Title: "Second"})
+			if err != nil {
+				t.Fatalf("Create() failed: %v", err)
+			}
+			if first.ID != 1 || second.ID != 2 {
+				t.Errorf("Expected IDs 1 and 2, got %d and %d", first.ID, second.ID)
+			}
+
+			got, err := store.Get(first.ID)
+			if err != nil {
+				t.Fatalf("Get() failed: %v", err)
+			}
+			if got.Title != "First" || got.Description != "one" || !got.CreatedAt.Equal(created) {
+				t.Errorf("Unexpected task: %+v", got)
+			}
+
+			if err := store.Update(first.ID, func(task *Task) error { task.Done = true; return nil }); err != nil {
+				t.Fatalf("Update() failed: %v", err)
+			}
+			failure := errors.New("rejected")
+			if err := store.Update(first.ID, func(task *Task) error { task.Title = "changed"; return failure }); !errors.Is(err, failure) {
+				t.Errorf("Expected fn error to be returned, got %v", err)
+			}
+			if got, _ := store.Get(first.ID); !got.Done || got.Title != "First" {
+				t.Errorf("Expected only the successful update to be stored, got %+v", got)
+			}
+
+			if err := store.Delete(second.ID); err != nil {
+				t.Fatalf("Delete() failed: %v", err)
+			}
+			for _, err := range []error{
+				store.Delete(second.ID),
+				store.Update(second.ID, func(task *Task) error { return nil }),
+			} {
+				if !errors.Is(err, ErrTaskNotFound) {
+					t.Errorf("Expected ErrTaskNotFound, got %v", err)
+				}
+			}
+			if _, err := store.Get(second.ID); !errors.Is(err, ErrTaskNotFound) {
+				t.Errorf("Expected ErrTaskNotFound, got %v", err)
+			}
+
+			third, err := store.Create(Task{Title: "Third"})
+			if err != nil {
+				t.Fatalf("Create() failed: %v", err)
+			}
+			if third.ID != 3 {
+				t.Errorf("Expected deleted IDs not to be reused, got %d", third.ID)
+			}
+
+			tasks, err := store.List()
+			if err != nil {
+				t.Fatalf("List() failed: %v", err)
+			}
+			if len(tasks) != 2 || tasks[0].ID != 1 || tasks[1].ID != 3 {
+				t.Errorf("Expected tasks 1 and 3 in order, got %+v", tasks)
+			}
+		})
+	}
+}
+
+func TestStoresSurviveRestart(t *testing.T) {
+	for name, factory := range storeFactories {
+		if factory == nil {
+			continue
+		}
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "tasks")
+
+			store := factory(t, path)
+			store.Create(Task{Title: "First"})
+			second, _ := store.Create(Task{Title: "Second"})
+			store.Delete(second.ID)
+			if err := store.Close(); err != nil {
+				t.Fatalf("Close() failed: %v", err)
+			}
+
+			reopened := openStore(t, name, path)
+			tasks, err := reopened.List()
+			if err != nil {
+				t.Fatalf("List() failed: %v", err)
+			}
+			if len(tasks) != 1 || tasks[0].Title != "First" {
+				t.Errorf("Expected the first task to be persisted, got %+v", tasks)
+			}
+			next, err := reopened.Create(Task{Title: "Third"})
+			if err != nil {
+				t.Fatalf("Create() failed: %v", err)
+			}
+			if next.ID != 3 {
+				t.Errorf("Expected ID allocation to continue at 3, got %d", next.ID)
+			}
+		})
+	}
+}
+
+func TestStoresConcurrentUse(t *testing.T) {
+	for name := range storeFactories {
+		t.Run(name, func(t *testing.T) {
+			tm := NewTaskManagerWithStore(openStore(t, name, filepath.Join(t.TempDir(), "tasks")))
+
+			const workers, perWorker = 8, 10
+			var wg sync.WaitGroup
+			ids := make(chan int, workers*perWorker)
+			for w := 0; w < workers; w++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for i := 0; i < perWorker; i++ {
+						task, err := tm.AddTask("Task", "")
+						if err != nil {
+							t.Errorf("AddTask() failed: %v", err)
+							return
+						}
+						if _, err := tm.UpdateTask(task.ID, TaskUpdate{Description: ptr("updated"), Done: ptr(true)}); err != nil {
+							t.Errorf("UpdateTask() failed: %v", err)
+						}
+						ids <- task.ID
+					}
+				}()
+			}
+			wg.Wait()
+			close(ids)
+
+			seen := make(map[int]bool)
+			for id := range ids {
+				if seen[id] {
+					t.Errorf("ID %d was allocated twice", id)
+				}
+				seen[id] = true
+			}
+			if len(seen) != workers*perWorker {
+				t.Errorf("Expected %d tasks, got %d", workers*perWorker, len(seen))
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package taskmanager
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Priority ranks tasks, higher values are more important
+type Priority int
+
+const (
+	PriorityNone Priority = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+	PriorityUrgent
+)
+
+var priorityNames = []string{"none", "low", "medium", "high", "urgent"}
+
+// ParsePriority converts a name such as "high" to a Priority, case-insensitively
+func ParsePriority(s string) (Priority, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	if name == "" {
+		return PriorityNone, nil
+	}
+	for i, n := range priorityNames {
+		if n == name {
+			return Priority(i), nil
+		}
+	}
+	return PriorityNone, fmt.Errorf("%w: %q", ErrInvalidPriority, s)
+}
+
+// Valid reports whether p is one of the defined priorities
+func (p Priority) Valid() bool {
+	return p >= PriorityNone && p <= PriorityUrgent
+}
+
+func (p Priority) String() string {
+	if !p.Valid() {
+		return fmt.Sprintf("Priority(%d)", int(p))
+	}
+	return priorityNames[p]
+}
+
+// MarshalText encodes the priority by name, so JSON files stay readable
+func (p Priority) MarshalText() ([]byte, error) {
+	if !p.Valid() {
+		return nil, ErrInvalidPriority
+	}
+	return []byte(p.String()), nil
+}
+
+func (p *Priority) UnmarshalText(text []byte) error {
+	parsed, err := ParsePriority(string(text))
+	if err != nil {
+		return err
+	}
+	*p = parsed
+	return nil
+}
+
+// Task represents a single task, subtasks point to their parent with ParentID
+type Task struct {
+	ID          int        `json:"id"`
+	ParentID    int        `json:"parent_id,omitempty"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Done        bool       `json:"done"`
+	Priority    Priority   `json:"priority"`
+	Tags        []string   `json:"tags,omitempty"`
+	DueAt       *time.Time `json:"due_at,omitempty"`
+	// Recurrence is a rule accepted by ParseRecurrence, empty for tasks that do not repeat
+	Recurrence  string     `json:"recurrence,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// HasTag reports whether the task carries tag, compared case-insensitively
+func (t Task) HasTag(tag string) bool {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	for _, existing := range t.Tags {
+		if existing == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// clone returns a deep copy so stores never share slices or pointers with callers
+func (t Task) clone() Task {
+	if t.Tags != nil {
+		t.Tags = append([]string{}, t.Tags...)
+	}
+	if t.DueAt != nil {
+		due := *t.DueAt
+		t.DueAt = &due
+	}
+	if t.CompletedAt != nil {
+		completed := *t.CompletedAt
+		t.CompletedAt = &completed
+	}
+	return t
+}
+
+// setDone changes the completion state and maintains CompletedAt
+func (t *Task) setDone(done bool, now time.Time) {
+	if t.Done == done {
+		return
+	}
+	t.Done = done
+	t.CompletedAt = nil
+	if done {
+		t.CompletedAt = &now
+	}
+}
+
+// normalizeTags trims and lowercases tags, drops empty ones and duplicates and sorts them
+func normalizeTags(tags []string) []string {
+	seen := make(map[string]bool)
+	result := []string{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			result = append(result, tag)
+		}
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	sort.Strings(result)
+	return result
+}
+
+// TaskOption sets optional fields of a new task
+type TaskOption func(task *Task)
+
+// WithDueAt sets the due date
+func WithDueAt(due time.Time) TaskOption {
+	return func(task *Task) {
+		task.DueAt = &due
+	}
+}
+
+// WithPriority sets the priority
+func WithPriority(priority Priority) TaskOption {
+	return func(task *Task) {
+		task.Priority = priority
+	}
+}
+
+// WithTags sets the tags, they are normalized to lowercase
+func WithTags(tags ...string) TaskOption {
+	return func(task *Task) {
+		task.Tags = tags
+	}
+}
+
+// WithRecurrence makes the task repeat, rule uses the syntax of ParseRecurrence
+func WithRecurrence(rule string) TaskOption {
+	return func(task *Task) {
+		task.Recurrence = rule
+	}
+}
+
+// WithParent makes the new task a subtask of parentID
+func WithParent(parentID int) TaskOption {
+	return func(task *Task) {
+		task.ParentID = parentID
+	}
+}
+
+// TaskUpdate lists the fields changed by UpdateTask, nil fields are left as they are
+type TaskUpdate struct {
+	Title       *string
+	Description *string
+	Done        *bool
+	Priority    *Priority
+	Tags        *[]string
+	DueAt       *time.Time
+	// Recurrence replaces the recurrence rule, the empty rule stops the task from repeating
+	Recurrence *string
+	// ClearDueAt removes the due date, DueAt is ignored when it is set
+	ClearDueAt bool
+	// ParentID moves the task under another parent, 0 makes it a top-level task
+	ParentID *int
+}
@@ -0,0 +1,223 @@
+package taskmanager
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newClockedManager returns a manager whose clock advances one minute per call
+func newClockedManager() *TaskManager {
+	tm := NewTaskManager()
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	tm.now = func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}
+	return tm
+}
+
+func TestAddTaskOptions(t *testing.T) {
+	tm := newClockedManager()
+	due := time.Date(2025, 7, 10, 17, 0, 0, 0, time.UTC)
+
+	task, err := tm.AddTask("Report", "Quarterly report",
+		WithDueAt(due), WithPriority(PriorityHigh), WithTags(" Work", "urgent", "work", ""))
+	if err != nil {
+		t.Fatalf("AddTask() failed: %v", err)
+	}
+	if task.DueAt == nil || !task.DueAt.Equal(due) {
+		t.Errorf("Expected due date %v, got %v", due, task.DueAt)
+	}
+	if task.Priority != PriorityHigh {
+		t.Errorf("Expected priority high, got %v", task.Priority)
+	}
+	if !reflect.DeepEqual(task.Tags, []string{"urgent", "work"}) {
+		t.Errorf("Expected normalized tags, got %v", task.Tags)
+	}
+	if !task.UpdatedAt.Equal(task.CreatedAt) || task.CompletedAt != nil {
+		t.Errorf("Unexpected timestamps: %+v", task)
+	}
+
+	if _, err := tm.AddTask("Bad", "", WithPriority(Priority(9))); !errors.Is(err, ErrInvalidPriority) {
+		t.Errorf("Expected ErrInvalidPriority, got %v", err)
+	}
+	if _, err := tm.AddTask("Orphan", "", WithParent(999)); !errors.Is(err, ErrInvalidParent) {
+		t.Errorf("Expected ErrInvalidParent, got %v", err)
+	}
+}
+
+func TestUpdateTaskPartial(t *testing.T) {
+	tm := newClockedManager()
+	due := time.Date(2025, 7, 10, 17, 0, 0, 0, time.UTC)
+	task, _ := tm.AddTask("Report", "Quarterly report", WithDueAt(due), WithTags("work"))
+
+	updated, err := tm.UpdateTask(task.ID, TaskUpdate{Title: ptr("Final report")})
+	if err != nil {
+		t.Fatalf("UpdateTask() failed: %v", err)
+	}
+	if updated.Title != "Final report" || updated.Description != "Quarterly report" ||
+		updated.DueAt == nil || !reflect.DeepEqual(updated.Tags, []string{"work"}) {
+		t.Errorf("Expected only the title to change, got %+v", updated)
+	}
+	if !updated.UpdatedAt.After(task.UpdatedAt) {
+		t.Error("Expected UpdatedAt to advance")
+	}
+
+	updated, _ = tm.UpdateTask(task.ID, TaskUpdate{Done: ptr(true), ClearDueAt: true, Priority: ptr(PriorityLow)})
+	if updated.CompletedAt == nil || !updated.CompletedAt.Equal(updated.UpdatedAt) {
+		t.Errorf("Expected CompletedAt to be set, got %v", updated.CompletedAt)
+	}
+	if updated.DueAt != nil || updated.Priority != PriorityLow {
+		t.Errorf("Expected due date cleared and priority low, got %+v", updated)
+	}
+
+	updated, _ = tm.UpdateTask(task.ID, TaskUpdate{Done: ptr(false), Tags: &[]string{}})
+	if updated.CompletedAt != nil || updated.Tags != nil {
+		t.Errorf("Expected CompletedAt and tags cleared, got %+v", updated)
+	}
+
+	tests := []struct {
+		name   string
+		update TaskUpdate
+		want   error
+	}{
+		{"empty title", TaskUpdate{Title: ptr("")}, ErrEmptyTitle},
+		{"invalid priority", TaskUpdate{Priority: ptr(Priority(-1))}, ErrInvalidPriority},
+		{"own parent", TaskUpdate{ParentID: ptr(task.ID)}, ErrInvalidParent},
+		{"unknown parent", TaskUpdate{ParentID: ptr(999)}, ErrInvalidParent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tm.UpdateTask(task.ID, tt.update); !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestSubtaskRollUp(t *testing.T) {
+	tm := newClockedManager()
+	project, _ := tm.AddTask("Project", "")
+	design, _ := tm.AddTask("Design", "", WithParent(project.ID))
+	build, _ := tm.AddTask("Build", "", WithParent(project.ID))
+	api, _ := tm.AddTask("API", "", WithParent(build.ID))
+
+	isDone := func(id int) bool {
+		task, err := tm.GetTask(id)
+		if err != nil {
+			t.Fatalf("GetTask(%d) failed: %v", id, err)
+		}
+		return task.Done
+	}
+
+	tm.UpdateTask(design.ID, TaskUpdate{Done: ptr(true)})
+	if isDone(project.ID) {
+		t.Error("Project must stay open while subtasks are open")
+	}
+	if done, total, _ := tm.Progress(project.ID); done != 1 || total != 2 {
+		t.Errorf("Expected progress 1/2, got %d/%d", done, total)
+	}
+
+	tm.UpdateTask(api.ID, TaskUpdate{Done: ptr(true)})
+	if !isDone(build.ID) || !isDone(project.ID) {
+		t.Error("Completing the last subtask must complete all ancestors")
+	}
+	if project, _ := tm.GetTask(project.ID); project.CompletedAt == nil {
+		t.Error("Rolled up completion must set CompletedAt")
+	}
+
+	tm.UpdateTask(api.ID, TaskUpdate{Done: ptr(false)})
+	if isDone(build.ID) || isDone(project.ID) {
+		t.Error("Reopening a subtask must reopen all ancestors")
+	}
+
+	tm.UpdateTask(project.ID, TaskUpdate{Done: ptr(true)})
+	for _, id := range []int{design.ID, build.ID, api.ID} {
+		if !isDone(id) {
+			t.Errorf("Completing the project must complete subtask %d", id)
+		}
+	}
+
+	docs, _ := tm.AddTask("Docs", "", WithParent(project.ID))
+	if isDone(project.ID) {
+		t.Error("Adding an open subtask must reopen the parent")
+	}
+
+	if _, err := tm.UpdateTask(project.ID, TaskUpdate{ParentID: ptr(api.ID)}); !errors.Is(err, ErrInvalidParent) {
+		t.Errorf("Expected ErrInvalidParent for a cycle, got %v", err)
+	}
+
+	if err := tm.DeleteTask(docs.ID); err != nil {
+		t.Fatalf("DeleteTask() failed: %v", err)
+	}
+	if !isDone(project.ID) {
+		t.Error("Deleting the only open subtask must complete the parent")
+	}
+
+	if err := tm.DeleteTask(build.ID); err != nil {
+		t.Fatalf("DeleteTask() failed: %v", err)
+	}
+	if _, err := tm.GetTask(api.ID); !errors.Is(err, ErrTaskNotFound) {
+		t.Errorf("Deleting a task must delete its subtasks, got %v", err)
+	}
+	if subtasks, _ := tm.Subtasks(project.ID); len(subtasks) != 1 || subtasks[0].ID != design.ID {
+		t.Errorf("Expected only the design subtask left, got %+v", subtasks)
+	}
+}
+
+func TestStoresPersistRichTasks(t *testing.T) {
+	for name := range storeFactories {
+		t.Run(name, func(t *testing.T) {
+			store := openStore(t, name, filepath.Join(t.TempDir(), "tasks"))
+			due := time.Date(2025, 7, 10, 17, 0, 0, 0, time.UTC)
+			completed := time.Date(2025, 7, 2, 8, 0, 0, 0, time.UTC)
+			created := time.Date(2025, 7, 1, 8, 0, 0, 0, time.UTC)
+
+			parent, _ := store.Create(Task{Title: "Parent", CreatedAt: created, UpdatedAt: created})
+			task, err := store.Create(Task{
+				ParentID: parent.ID, Title: "Child", Done: true, Priority: PriorityUrgent,
+				Tags: []string{"a", "b"}, DueAt: &due, CreatedAt: created, UpdatedAt: completed, CompletedAt: &completed,
+			})
+			if err != nil {
+				t.Fatalf("Create() failed: %v", err)
+			}
+
+			got, err := store.Get(task.ID)
+			if err != nil {
+				t.Fatalf("Get() failed: %v", err)
+			}
+			if got.ParentID != parent.ID || got.Priority != PriorityUrgent || !reflect.DeepEqual(got.Tags, []string{"a", "b"}) ||
+				got.DueAt == nil || !got.DueAt.Equal(due) || got.CompletedAt == nil || !got.CompletedAt.Equal(completed) ||
+				!got.UpdatedAt.Equal(completed) {
+				t.Errorf("Task did not round-trip: %+v", got)
+			}
+
+			got, _ = store.Get(parent.ID)
+			if got.ParentID != 0 || got.Tags != nil || got.DueAt != nil || got.CompletedAt != nil {
+				t.Errorf("Expected empty optional fields, got %+v", got)
+			}
+		})
+	}
+}
+
+func TestPriorityText(t *testing.T) {
+	data, err := json.Marshal(Task{Priority: PriorityHigh})
+	if err != nil {
+		t.Fatalf("Marshal() failed: %v", err)
+	}
+	var decoded Task
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.Priority != PriorityHigh {
+		t.Errorf("Expected priority to round-trip, got %v (%v) from %s", decoded.Priority, err, data)
+	}
+
+	if p, err := ParsePriority(" Urgent "); err != nil || p != PriorityUrgent {
+		t.Errorf("ParsePriority() = %v, %v", p, err)
+	}
+	if _, err := ParsePriority("critical"); !errors.Is(err, ErrInvalidPriority) {
+		t.Errorf("Expected ErrInvalidPriority, got %v", err)
+	}
+}
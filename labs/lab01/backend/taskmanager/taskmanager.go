@@ -2,108 +2,390 @@ package taskmanager
 
 import (
 	"errors"
+	"sync"
 	"time"
 )
 
 // Predefined errors
 var (
-	ErrTaskNotFound = errors.New("task not found")
-	ErrEmptyTitle   = errors.New("title cannot be empty")
+	ErrTaskNotFound    = errors.New("task not found")
+	ErrEmptyTitle      = errors.New("title cannot be empty")
+	ErrInvalidPriority = errors.New("invalid priority")
+	ErrInvalidParent   = errors.New("invalid parent task")
 )
 
-// Task represents a single task
-type Task struct {
-	ID          int
-	Title       string
-	Description string
-	Done        bool
-	CreatedAt   time.Time
-}
-
-// TaskManager manages a collection of tasks
+// TaskManager manages a collection of tasks kept in a TaskStore, it is safe for concurrent use.
+//
+// Completion rolls up through subtasks: completing the last open subtask
+// completes the parent, reopening or adding a subtask reopens it, and
+// completing a parent completes all of its subtasks. However a recurring task
+// is completed, its next occurrence is added as a new open task.
 type TaskManager struct {
-	tasks  map[int]Task
-	nextID int
+	store TaskStore
+	now   func() time.Time
+	// mu serializes changes that touch several tasks, such as the completion roll-up
+	mu sync.Mutex
 }
 
-// NewTaskManager creates a new task manager
+// NewTaskManager creates a new task manager with an in-memory store
 func NewTaskManager() *TaskManager {
-	return &TaskManager{
-		tasks:  make(map[int]Task),
-		nextID: 1,
-	}
+	return NewTaskManagerWithStore(NewMemoryStore())
+}
+
+// NewTaskManagerWithStore creates a task manager on top of store
+func NewTaskManagerWithStore(store TaskStore) *TaskManager {
+	return &TaskManager{store: store, now: time.Now}
+}
+
+// Close closes the underlying store
+func (tm *TaskManager) Close() error {
+	return tm.store.Close()
 }
 
-// AddTask adds a new task to the manager, returns an error if the title is empty, and increments the nextID
-func (tm *TaskManager) AddTask(title, description string) (Task, error) {
+// AddTask adds a new task to the manager, returns an error if the title is empty, the store assigns the ID
+func (tm *TaskManager) AddTask(title, description string, opts ...TaskOption) (Task, error) {
 	if title == "" {
 		return Task{}, ErrEmptyTitle
 	}
 
+	now := tm.now()
 	task := Task{
-		ID:          tm.nextID,
 		Title:       title,
 		Description: description,
 		Done:        false,
-		CreatedAt:   time.Now(),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	for _, opt := range opts {
+		opt(&task)
+	}
+	task.Tags = normalizeTags(task.Tags)
+	if !task.Priority.Valid() {
+		return Task{}, ErrInvalidPriority
 	}
+	rule, err := validRecurrence(task.Recurrence)
+	if err != nil {
+		return Task{}, err
+	}
+	task.Recurrence = rule
 
-	tm.tasks[tm.nextID] = task
-	tm.nextID++
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
 
-	return task, nil
+	if task.ParentID != 0 {
+		if _, err := tm.store.Get(task.ParentID); err != nil {
+			if errors.Is(err, ErrTaskNotFound) {
+				return Task{}, ErrInvalidParent
+			}
+			return Task{}, err
+		}
+	}
+
+	created, err := tm.store.Create(task)
+	if err != nil {
+		return Task{}, err
+	}
+	if err := tm.rollUp(created.ParentID, now); err != nil {
+		return Task{}, err
+	}
+	return created, nil
 }
 
-// UpdateTask updates an existing task, returns an error if the title is empty or the task is not found
-func (tm *TaskManager) UpdateTask(id int, title, description string, done bool) error {
+// UpdateTask changes the fields set in update and returns the updated task.
+// Completing a recurring task adds its next occurrence as a new open task, which takes over the recurrence rule.
+// It returns an error if the title is empty, the priority, recurrence or parent is invalid or the task is not found.
+func (tm *TaskManager) UpdateTask(id int, update TaskUpdate) (Task, error) {
+	if update.Title != nil && *update.Title == "" {
+		return Task{}, ErrEmptyTitle
+	}
+	if update.Priority != nil && !update.Priority.Valid() {
+		return Task{}, ErrInvalidPriority
+	}
+	var rule *string
+	if update.Recurrence != nil {
+		normalized, err := validRecurrence(*update.Recurrence)
+		if err != nil {
+			return Task{}, err
+		}
+		rule = &normalized
+	}
+
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
 
-	task, exists := tm.tasks[id]
-	if !exists {
-		return ErrTaskNotFound
+	current, err := tm.store.Get(id)
+	if err != nil {
+		return Task{}, err
+	}
+	if update.ParentID != nil {
+		if err := tm.checkParent(id, *update.ParentID); err != nil {
+			return Task{}, err
+		}
 	}
 
-	if title == "" {
-		return ErrEmptyTitle
+	now := tm.now()
+	updated, err := tm.update(id, now, func(task *Task) {
+		if update.Title != nil {
+			task.Title = *update.Title
+		}
+		if update.Description != nil {
+			task.Description = *update.Description
+		}
+		if rule != nil {
+			task.Recurrence = *rule
+		}
+		if update.Done != nil {
+			task.setDone(*update.Done, now)
+		}
+		if update.Priority != nil {
+			task.Priority = *update.Priority
+		}
+		if update.Tags != nil {
+			task.Tags = normalizeTags(*update.Tags)
+		}
+		if update.ClearDueAt {
+			task.DueAt = nil
+		} else if update.DueAt != nil {
+			due := *update.DueAt
+			task.DueAt = &due
+		}
+		if update.ParentID != nil {
+			task.ParentID = *update.ParentID
+		}
+		task.UpdatedAt = now
+	})
+	if err != nil {
+		return Task{}, err
 	}
 
-	task.Title = title
-	task.Description = description
-	task.Done = done
-	tm.tasks[id] = task
-	return nil
+	if updated.Done && !current.Done {
+		if err := tm.completeSubtasks(id, now); err != nil {
+			return Task{}, err
+		}
+	}
+	if err := tm.rollUp(updated.ParentID, now); err != nil {
+		return Task{}, err
+	}
+	if current.ParentID != updated.ParentID {
+		if err := tm.rollUp(current.ParentID, now); err != nil {
+			return Task{}, err
+		}
+	}
+	return tm.store.Get(id)
 }
 
-// DeleteTask removes a task from the manager, returns an error if the task is not found
+// DeleteTask removes a task and all of its subtasks from the manager, returns an error if the task is not found
 func (tm *TaskManager) DeleteTask(id int) error {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
 
-	if _, exists := tm.tasks[id]; !exists {
-		return ErrTaskNotFound
+	task, err := tm.store.Get(id)
+	if err != nil {
+		return err
+	}
+	tasks, err := tm.store.List()
+	if err != nil {
+		return err
 	}
 
-	delete(tm.tasks, id)
-	return nil
+	// Subtasks first, so a failure never leaves orphans behind
+	ids := descendants(tasks, id)
+	for i := len(ids) - 1; i >= 0; i-- {
+		if err := tm.store.Delete(ids[i]); err != nil && !errors.Is(err, ErrTaskNotFound) {
+			return err
+		}
+	}
+	if err := tm.store.Delete(id); err != nil {
+		return err
+	}
+	return tm.rollUp(task.ParentID, tm.now())
 }
 
 // GetTask retrieves a task by ID, returns an error if the task is not found
 func (tm *TaskManager) GetTask(id int) (Task, error) {
+	return tm.store.Get(id)
+}
 
-	task, exists := tm.tasks[id]
-	if !exists {
-		return Task{}, ErrTaskNotFound
+// Subtasks returns the direct subtasks of a task ordered by ID, returns an error if the task is not found
+func (tm *TaskManager) Subtasks(id int) ([]Task, error) {
+	if _, err := tm.store.Get(id); err != nil {
+		return nil, err
+	}
+	tasks, err := tm.store.List()
+	if err != nil {
+		return nil, err
 	}
+	return children(tasks, id), nil
+}
 
-	return task, nil
+// Progress returns how many of the direct subtasks of a task are done
+func (tm *TaskManager) Progress(id int) (done, total int, err error) {
+	subtasks, err := tm.Subtasks(id)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, task := range subtasks {
+		if task.Done {
+			done++
+		}
+	}
+	return done, len(subtasks), nil
 }
 
-// ListTasks returns all tasks, optionally filtered by done status, returns an empty slice if no tasks are found
-func (tm *TaskManager) ListTasks(filterDone *bool) []Task {
-	result := []Task{}
+// update applies change to the task id and returns the result. Every change that may complete
+// a task goes through update: when it completes a recurring task, the next occurrence is created
+// first and takes over the rule, and it is deleted again if storing the change fails.
+// change runs twice, on a copy to preview the result and in the store, so it must not depend on anything else.
+func (tm *TaskManager) update(id int, now time.Time, change func(task *Task)) (Task, error) {
+	current, err := tm.store.Get(id)
+	if err != nil {
+		return Task{}, err
+	}
+	preview := current.clone()
+	change(&preview)
+	completes := preview.Done && !current.Done && preview.Recurrence != ""
+	nextID := 0
+	if completes {
+		if nextID, err = tm.addNextOccurrence(preview, now); err != nil {
+			return Task{}, err
+		}
+	}
+
+	var updated Task
+	err = tm.store.Update(id, func(task *Task) error {
+		change(task)
+		if completes {
+			task.Recurrence = ""
+		}
+		updated = *task
+		return nil
+	})
+	if err != nil {
+		if nextID != 0 {
+			tm.store.Delete(nextID)
+		}
+		return Task{}, err
+	}
+	return updated, nil
+}
+
+// addNextOccurrence creates the open task that follows the completed recurring task and returns its ID,
+// nothing is created and the ID is 0 once the rule has no further occurrences
+func (tm *TaskManager) addNextOccurrence(task Task, now time.Time) (int, error) {
+	rule, err := ParseRecurrence(task.Recurrence)
+	if err != nil {
+		return 0, err
+	}
+	next := nextOccurrence(task, rule, now)
+	if next.IsZero() {
+		return 0, nil
+	}
+
+	created, err := tm.store.Create(Task{
+		ParentID:    task.ParentID,
+		Title:       task.Title,
+		Description: task.Description,
+		Priority:    task.Priority,
+		Tags:        task.Tags,
+		DueAt:       &next,
+		Recurrence:  task.Recurrence,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+	return created.ID, err
+}
+
+// checkParent rejects a parent that does not exist or would create a cycle
+func (tm *TaskManager) checkParent(id, parentID int) error {
+	for current := parentID; current != 0; {
+		if current == id {
+			return ErrInvalidParent
+		}
+		parent, err := tm.store.Get(current)
+		if errors.Is(err, ErrTaskNotFound) {
+			return ErrInvalidParent
+		}
+		if err != nil {
+			return err
+		}
+		current = parent.ParentID
+	}
+	return nil
+}
+
+// rollUp marks parentID done or open to match its subtasks and continues with its own parent
+func (tm *TaskManager) rollUp(parentID int, now time.Time) error {
+	for parentID != 0 {
+		// Listed on every round, completing a recurring parent adds its next occurrence next to it
+		tasks, err := tm.store.List()
+		if err != nil {
+			return err
+		}
+		subtasks := children(tasks, parentID)
+		if len(subtasks) == 0 {
+			return nil
+		}
+		allDone := true
+		for _, task := range subtasks {
+			allDone = allDone && task.Done
+		}
+
+		changed := false
+		var next int
+		_, err = tm.update(parentID, now, func(task *Task) {
+			next = task.ParentID
+			changed = task.Done != allDone
+			if changed {
+				task.setDone(allDone, now)
+				task.UpdatedAt = now
+			}
+		})
+		if err != nil || !changed {
+			return err
+		}
+		parentID = next
+	}
+	return nil
+}
 
-	for _, task := range tm.tasks {
-		if filterDone == nil || *filterDone == task.Done {
+// completeSubtasks marks every open subtask of id done, recursively.
+// Next occurrences of recurring subtasks are added under the same parent and stay open.
+func (tm *TaskManager) completeSubtasks(id int, now time.Time) error {
+	tasks, err := tm.store.List()
+	if err != nil {
+		return err
+	}
+	for _, child := range descendants(tasks, id) {
+		_, err := tm.update(child, now, func(task *Task) {
+			if !task.Done {
+				task.setDone(true, now)
+				task.UpdatedAt = now
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// children returns the direct subtasks of id
+func children(tasks []Task, id int) []Task {
+	result := []Task{}
+	for _, task := range tasks {
+		if task.ParentID == id {
 			result = append(result, task)
 		}
 	}
+	return result
+}
 
+// descendants returns the IDs of all subtasks below id, parents before their subtasks
+func descendants(tasks []Task, id int) []int {
+	var result []int
+	for _, child := range children(tasks, id) {
+		result = append(result, child.ID)
+		result = append(result, descendants(tasks, child.ID)...)
+	}
 	return result
 }
@@ -4,16 +4,24 @@ import (
 	"testing"
 )
 
+func ptr[T any](v T) *T {
+	return &v
+}
+
 func TestNewTaskManager(t *testing.T) {
 	tm := NewTaskManager()
 	if tm == nil {
 		t.Error("NewTaskManager() returned nil")
 	}
-	if tm.tasks == nil {
-		t.Error("tasks map is nil")
+	if tm.store == nil {
+		t.Error("store is nil")
 	}
-	if tm.nextID != 1 {
-		t.Errorf("Expected nextID to be 1, got %d", tm.nextID)
+	task, err := tm.AddTask("First", "")
+	if err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	if task.ID != 1 {
+		t.Errorf("Expected first ID to be 1, got %d", task.ID)
 	}
 }
 
@@ -167,7 +175,7 @@ func TestUpdateTask(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := tm.UpdateTask(tt.id, tt.title, tt.description, tt.done)
+			_, err := tm.UpdateTask(tt.id, TaskUpdate{Title: &tt.title, Description: &tt.description, Done: &tt.done})
 
 			if tt.expectError {
 				if err == nil {
@@ -262,7 +270,7 @@ func TestListTasks(t *testing.T) {
 	_, _ = tm.AddTask("Task 3", "Description 3")
 
 	// Mark one task as done
-	tm.UpdateTask(task2.ID, task2.Title, task2.Description, true)
+	tm.UpdateTask(task2.ID, TaskUpdate{Done: ptr(true)})
 
 	tests := []struct {
 		name     string
@@ -288,7 +296,11 @@ func TestListTasks(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			tasks := tm.ListTasks(tt.filter)
+			page, err := tm.ListTasks(TaskQuery{Done: tt.filter})
+			if err != nil {
+				t.Fatalf("ListTasks() failed: %v", err)
+			}
+			tasks := page.Tasks
 			if len(tasks) != tt.expected {
 				t.Errorf("ListTasks() returned %d tasks, want %d", len(tasks), tt.expected)
 			}
@@ -0,0 +1,323 @@
+package taskmanager
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format is a file format understood by Export and Import
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatCSV  Format = "csv"
+	// FormatICal is iCalendar with one VTODO per task, as used by calendar apps
+	FormatICal Format = "ics"
+)
+
+// ErrUnknownFormat is returned by Export and Import for a format they do not support
+var ErrUnknownFormat = errors.New("unknown format")
+
+// RowError describes one malformed task of an import
+type RowError struct {
+	// Row is the position of the task in a JSON array, or the line it starts on in CSV and iCalendar files
+	Row int
+	// Field names the offending field or property, it is empty when the whole row is malformed
+	Field string
+	Err   error
+}
+
+func (e *RowError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("row %d: %v", e.Row, e.Err)
+	}
+	return fmt.Sprintf("row %d: %s: %v", e.Row, e.Field, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// ImportError lists every malformed row of an import, no task is imported when it is returned
+type ImportError struct {
+	Rows []*RowError
+}
+
+func (e *ImportError) Error() string {
+	msgs := make([]string, len(e.Rows))
+	for i, row := range e.Rows {
+		msgs[i] = row.Error()
+	}
+	return fmt.Sprintf("import failed, %d malformed rows: %s", len(e.Rows), strings.Join(msgs, "; "))
+}
+
+func (e *ImportError) Unwrap() []error {
+	errs := make([]error, len(e.Rows))
+	for i, row := range e.Rows {
+		errs[i] = row
+	}
+	return errs
+}
+
+// importRow is a decoded task before validation, ref and parentRef link subtasks to their parent within the file
+type importRow struct {
+	row       int
+	ref       string
+	parentRef string
+	task      Task
+	errs      []*RowError
+	// undecoded rows failed as a whole, their task holds no reliable fields
+	undecoded bool
+}
+
+func (r *importRow) fail(field string, err error) {
+	r.errs = append(r.errs, &RowError{Row: r.row, Field: field, Err: err})
+}
+
+// Export writes every task ordered by ID in format
+func (tm *TaskManager) Export(w io.Writer, format Format) error {
+	tasks, err := tm.store.List()
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case FormatJSON:
+		return exportJSON(w, tasks)
+	case FormatCSV:
+		return exportCSV(w, tasks)
+	case FormatICal:
+		return exportICal(w, tasks, tm.now())
+	}
+	return fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+}
+
+// Import adds the tasks read from r in format and returns them with their new IDs in file order.
+// IDs in the file only link subtasks to their parent, the store assigns new ones.
+// Malformed rows are reported together in an *ImportError and nothing is imported.
+func (tm *TaskManager) Import(r io.Reader, format Format) ([]Task, error) {
+	var (
+		rows  []*importRow
+		names map[string]string
+		err   error
+	)
+	switch format {
+	case FormatJSON:
+		rows, err = decodeJSON(r)
+	case FormatCSV:
+		rows, err = decodeCSV(r)
+	case FormatICal:
+		rows, err = decodeICal(r)
+		names = icalFieldNames
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := tm.now()
+	order, err := prepareImport(rows, names, now)
+	if err != nil {
+		return nil, err
+	}
+
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	// Everything missing from this list when the import fails is undone, including
+	// next occurrences added when the roll-up completes a recurring task
+	existing, err := tm.store.List()
+	if err != nil {
+		return nil, err
+	}
+	result, err := tm.importRows(rows, order, now)
+	if err != nil {
+		tm.deleteImported(existing)
+		return nil, err
+	}
+	return result, nil
+}
+
+// importRows creates the tasks of order, rolls up their parents and returns them in the order of rows
+func (tm *TaskManager) importRows(rows, order []*importRow, now time.Time) ([]Task, error) {
+	ids := make(map[string]int)
+	for _, row := range order {
+		row.task.ParentID = ids[row.parentRef]
+		task, err := tm.store.Create(row.task)
+		if err != nil {
+			return nil, fmt.Errorf("failed to import row %d: %w", row.row, err)
+		}
+		if row.ref != "" {
+			ids[row.ref] = task.ID
+		}
+		row.task = task
+	}
+
+	// Bring parents in line with their imported subtasks
+	for _, row := range rows {
+		if err := tm.rollUp(row.task.ParentID, now); err != nil {
+			return nil, err
+		}
+	}
+
+	result := make([]Task, len(rows))
+	for i, row := range rows {
+		var err error
+		if result[i], err = tm.store.Get(row.task.ID); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// deleteImported undoes a failed import as far as the store allows, removing every task
+// that is not in existing, newest first
+func (tm *TaskManager) deleteImported(existing []Task) {
+	tasks, err := tm.store.List()
+	if err != nil {
+		return
+	}
+	kept := make(map[int]bool, len(existing))
+	for _, task := range existing {
+		kept[task.ID] = true
+	}
+	for i := len(tasks) - 1; i >= 0; i-- {
+		if !kept[tasks[i].ID] {
+			tm.store.Delete(tasks[i].ID)
+		}
+	}
+}
+
+// prepareImport validates and normalizes rows and returns them with parents before their subtasks.
+// names maps the field names used in errors to the names of the file format.
+func prepareImport(rows []*importRow, names map[string]string, now time.Time) ([]*importRow, error) {
+	field := func(name string) string {
+		if mapped, ok := names[name]; ok {
+			return mapped
+		}
+		return name
+	}
+
+	refs := make(map[string]*importRow)
+	for _, row := range rows {
+		if row.ref != "" {
+			if refs[row.ref] != nil {
+				row.fail(field("id"), fmt.Errorf("duplicate id %q", row.ref))
+			} else {
+				refs[row.ref] = row
+			}
+		}
+		if row.undecoded {
+			continue
+		}
+
+		task := &row.task
+		task.ID, task.ParentID = 0, 0
+		if strings.TrimSpace(task.Title) == "" {
+			row.fail(field("title"), ErrEmptyTitle)
+		}
+		if !task.Priority.Valid() {
+			row.fail(field("priority"), ErrInvalidPriority)
+		}
+		rule, err := validRecurrence(task.Recurrence)
+		if err != nil {
+			row.fail(field("recurrence"), err)
+		}
+		task.Recurrence = rule
+		task.Tags = normalizeTags(task.Tags)
+
+		if task.CreatedAt.IsZero() {
+			task.CreatedAt = now
+		}
+		if task.UpdatedAt.IsZero() {
+			task.UpdatedAt = task.CreatedAt
+		}
+		if !task.Done {
+			task.CompletedAt = nil
+		} else if task.CompletedAt == nil {
+			completed := task.UpdatedAt
+			task.CompletedAt = &completed
+		}
+	}
+
+	for _, row := range rows {
+		if row.parentRef != "" && refs[row.parentRef] == nil {
+			row.fail(field("parent_id"), fmt.Errorf("no task with id %q in the import", row.parentRef))
+		}
+	}
+
+	// Place parents first, whatever is left over is part of a cycle
+	placed := make(map[*importRow]bool)
+	var order []*importRow
+	for progress := true; progress; {
+		progress = false
+		for _, row := range rows {
+			parent := refs[row.parentRef]
+			if !placed[row] && (parent == nil || placed[parent]) {
+				placed[row] = true
+				order = append(order, row)
+				progress = true
+			}
+		}
+	}
+	for _, row := range rows {
+		if !placed[row] {
+			row.fail(field("parent_id"), errors.New("subtasks form a cycle"))
+		}
+	}
+
+	var errs []*RowError
+	for _, row := range rows {
+		errs = append(errs, row.errs...)
+	}
+	if len(errs) > 0 {
+		return nil, &ImportError{Rows: errs}
+	}
+	return order, nil
+}
+
+// exportJSON writes the tasks as an indented JSON array
+func exportJSON(w io.Writer, tasks []Task) error {
+	if tasks == nil {
+		tasks = []Task{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(tasks)
+}
+
+// decodeJSON reads a JSON array of tasks, each element is decoded on its own so one bad task does not hide the others
+func decodeJSON(r io.Reader) ([]*importRow, error) {
+	var raw []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to decode JSON: %w", err)
+	}
+
+	rows := make([]*importRow, len(raw))
+	for i, msg := range raw {
+		row := &importRow{row: i + 1}
+		rows[i] = row
+		if err := json.Unmarshal(msg, &row.task); err != nil {
+			var typeErr *json.UnmarshalTypeError
+			field := ""
+			if errors.As(err, &typeErr) {
+				field = typeErr.Field
+			}
+			row.fail(field, err)
+			row.undecoded = true
+		}
+		if row.task.ID != 0 {
+			row.ref = strconv.Itoa(row.task.ID)
+		}
+		if row.task.ParentID != 0 {
+			row.parentRef = strconv.Itoa(row.task.ParentID)
+		}
+	}
+	return rows, nil
+}
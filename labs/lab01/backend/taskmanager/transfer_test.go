@@ -0,0 +1,391 @@
+package taskmanager
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var transferFormats = []Format{FormatJSON, FormatCSV, FormatICal}
+
+// newTransferManager returns a manager with a fixed clock holding tasks that use every field
+func newTransferManager(t *testing.T) *TaskManager {
+	t.Helper()
+	tm := newClockedManager()
+	due := time.Date(2025, 7, 10, 17, 30, 0, 0, time.UTC)
+	project, err := tm.AddTask("Launch; phase 1, \"beta\"", "Line one\nLine two with a backslash \\ and a very long tail that needs folding in iCalendar — ünïcödé included",
+		WithPriority(PriorityUrgent), WithTags("work", "q3 goals", `r&d; ops\team`), WithDueAt(due))
+	if err != nil {
+		t.Fatalf("AddTask() failed: %v", err)
+	}
+	steps := []struct {
+		title string
+		opts  []TaskOption
+	}{
+		{"Write docs", []TaskOption{WithParent(project.ID), WithPriority(PriorityLow)}},
+		{"Ship", []TaskOption{WithParent(project.ID), WithPriority(PriorityMedium), WithRecurrence("every 2 weeks")}},
+		{"Standup", []TaskOption{WithPriority(PriorityHigh), WithRecurrence("30 9 * * 1-5"), WithDueAt(due.Add(-time.Hour))}},
+		{"Buy milk", nil},
+	}
+	for _, step := range steps {
+		if _, err := tm.AddTask(step.title, "", step.opts...); err != nil {
+			t.Fatalf("AddTask() failed: %v", err)
+		}
+	}
+	tm.UpdateTask(2, TaskUpdate{Done: ptr(true)})
+	tm.UpdateTask(5, TaskUpdate{Done: ptr(true)})
+	return tm
+}
+
+func export(t *testing.T, tm *TaskManager, format Format) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := tm.Export(&buf, format); err != nil {
+		t.Fatalf("Export() failed: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	for _, format := range transferFormats {
+		t.Run(string(format), func(t *testing.T) {
+			source := newTransferManager(t)
+			data := export(t, source, format)
+
+			target := newClockedManager()
+			imported, err := target.Import(bytes.NewReader(data), format)
+			if err != nil {
+				t.Fatalf("Import() failed: %v\n%s", err, data)
+			}
+
+			want, _ := source.ListTasks(TaskQuery{})
+			if len(imported) != len(want.Tasks) {
+				t.Fatalf("Expected %d imported tasks, got %d", len(want.Tasks), len(imported))
+			}
+			for i, task := range imported {
+				if !sameTask(task, want.Tasks[i]) {
+					t.Errorf("Task did not round-trip:\nwant %+v\ngot  %+v", want.Tasks[i], task)
+				}
+			}
+
+			// Exporting the imported tasks again gives the same file
+			source.now = func() time.Time { return time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC) }
+			target.now = source.now
+			if again, first := export(t, target, format), export(t, source, format); !bytes.Equal(again, first) {
+				t.Errorf("Export after import differs:\n%s\n---\n%s", first, again)
+			}
+		})
+	}
+}
+
+// sameTask compares tasks field by field, times by instant
+func sameTask(a, b Task) bool {
+	sameTime := func(x, y *time.Time) bool {
+		return (x == nil && y == nil) || (x != nil && y != nil && x.Equal(*y))
+	}
+	return a.ID == b.ID && a.ParentID == b.ParentID && a.Title == b.Title && a.Description == b.Description &&
+		a.Done == b.Done && a.Priority == b.Priority && reflect.DeepEqual(a.Tags, b.Tags) &&
+		a.Recurrence == b.Recurrence && sameTime(a.DueAt, b.DueAt) && sameTime(&a.CreatedAt, &b.CreatedAt) &&
+		sameTime(&a.UpdatedAt, &b.UpdatedAt) && sameTime(a.CompletedAt, b.CompletedAt)
+}
+
+func TestExportEmpty(t *testing.T) {
+	tm := NewTaskManager()
+	for _, format := range transferFormats {
+		t.Run(string(format), func(t *testing.T) {
+			imported, err := NewTaskManager().Import(bytes.NewReader(export(t, tm, format)), format)
+			if err != nil || len(imported) != 0 {
+				t.Errorf("Expected an empty import, got %v, %v", imported, err)
+			}
+		})
+	}
+}
+
+func TestExportICalFolding(t *testing.T) {
+	data := string(export(t, newTransferManager(t), FormatICal))
+	if !strings.HasPrefix(data, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n") {
+		t.Errorf("Expected a VCALENDAR with CRLF line endings, got %q", data[:40])
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(data, "\r\n"), "\r\n") {
+		if len(line) > icalLineLimit {
+			t.Errorf("Line longer than %d octets: %q", icalLineLimit, line)
+		}
+	}
+	for _, want := range []string{
+		`SUMMARY:Launch\; phase 1\, "beta"`,
+		"RRULE:FREQ=WEEKLY;INTERVAL=2",
+		"X-TASKMANAGER-RECURRENCE:30 9 * * 1-5",
+		"RELATED-TO;RELTYPE=PARENT:task-1@taskmanager",
+		`CATEGORIES:q3 goals,r&d\; ops\\team,work`,
+		"PRIORITY:1",
+	} {
+		if !strings.Contains(data, want) {
+			t.Errorf("Expected %q in export", want)
+		}
+	}
+}
+
+func TestImportICalFromCalendarApp(t *testing.T) {
+	data := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"PRODID:-//Example//Calendar//EN",
+		"BEGIN:VTIMEZONE",
+		"TZID:Europe/Berlin",
+		"END:VTIMEZONE",
+		"BEGIN:VEVENT",
+		"SUMMARY:Not a task",
+		"END:VEVENT",
+		"BEGIN:VTODO",
+		"UID:abc-1",
+		"SUMMARY:Plan the",
+		"  trip",
+		"CATEGORIES:Travel",
+		"CATEGORIES:Family\\,Friends",
+		"PRIORITY:2",
+		`DUE;TZID="Europe/Berlin":20250710T090000`,
+		"RRULE:FREQ=DAILY;INTERVAL=3",
+		"BEGIN:VALARM",
+		"SUMMARY:Alarm",
+		"END:VALARM",
+		"END:VTODO",
+		"BEGIN:VTODO",
+		"UID:abc-2",
+		"SUMMARY:Book hotel",
+		"RELATED-TO:abc-1",
+		"STATUS:COMPLETED",
+		"DUE;VALUE=DATE:20250705",
+		"END:VTODO",
+		"END:VCALENDAR",
+	}, "\n")
+
+	tm := NewTaskManager()
+	imported, err := tm.Import(strings.NewReader(data), FormatICal)
+	if err != nil {
+		t.Fatalf("Import() failed: %v", err)
+	}
+	if len(imported) != 2 {
+		t.Fatalf("Expected 2 tasks, got %+v", imported)
+	}
+
+	plan, hotel := imported[0], imported[1]
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	if plan.Title != "Plan the trip" || plan.Priority != PriorityUrgent ||
+		!reflect.DeepEqual(plan.Tags, []string{"family,friends", "travel"}) ||
+		plan.DueAt == nil || !plan.DueAt.Equal(time.Date(2025, 7, 10, 9, 0, 0, 0, berlin)) {
+		t.Errorf("Unexpected first task %+v", plan)
+	}
+	if hotel.ParentID != plan.ID || !hotel.Done || hotel.CompletedAt == nil ||
+		hotel.DueAt == nil || !hotel.DueAt.Equal(time.Date(2025, 7, 5, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("Unexpected second task %+v", hotel)
+	}
+	if !plan.Done {
+		t.Error("Parent must roll up to done when its only subtask is done")
+	}
+
+	// Completed by the roll-up, the recurring parent hands its rule to the next occurrence
+	open, _ := tm.ListTasks(TaskQuery{Done: ptr(false)})
+	if plan.Recurrence != "" || len(open.Tasks) != 1 || open.Tasks[0].Title != "Plan the trip" ||
+		open.Tasks[0].Recurrence != "every 3 days" {
+		t.Errorf("Expected the next occurrence to take over the rule, got %+v and %+v", plan, open.Tasks)
+	}
+}
+
+func TestImportICalForeignRRuleWithOwnRule(t *testing.T) {
+	// Edited by a calendar app that rewrote RRULE with parts we cannot convert, our own rule still wins
+	data := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VTODO",
+		"UID:task-1@taskmanager",
+		"SUMMARY:Standup",
+		"RRULE:FREQ=WEEKLY;BYDAY=MO,WE",
+		"X-TASKMANAGER-RECURRENCE:30 9 * * 1\\,3",
+		"END:VTODO",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	tm := newClockedManager()
+	imported, err := tm.Import(strings.NewReader(data), FormatICal)
+	if err != nil {
+		t.Fatalf("Import() failed: %v", err)
+	}
+	if len(imported) != 1 || imported[0].Recurrence != "30 9 * * 1,3" {
+		t.Fatalf("Expected the X-TASKMANAGER-RECURRENCE rule, got %+v", imported)
+	}
+
+	target := newClockedManager()
+	again, err := target.Import(bytes.NewReader(export(t, tm, FormatICal)), FormatICal)
+	if err != nil {
+		t.Fatalf("Import() of the export failed: %v", err)
+	}
+	if len(again) != 1 || !sameTask(again[0], imported[0]) {
+		t.Errorf("Task did not round-trip:\nwant %+v\ngot  %+v", imported, again)
+	}
+
+	// Without our own rule the unsupported RRULE is still reported
+	foreign := strings.Replace(data, "X-TASKMANAGER-RECURRENCE:30 9 * * 1\\,3\r\n", "", 1)
+	_, err = newClockedManager().Import(strings.NewReader(foreign), FormatICal)
+	if !errors.Is(err, ErrInvalidRecurrence) {
+		t.Errorf("Expected ErrInvalidRecurrence without X-TASKMANAGER-RECURRENCE, got %v", err)
+	}
+}
+
+func TestImportMalformed(t *testing.T) {
+	type rowErr struct {
+		row   int
+		field string
+	}
+	tests := []struct {
+		name   string
+		format Format
+		input  string
+		want   []rowErr
+		is     error
+	}{
+		{
+			name:   "json bad rows",
+			format: FormatJSON,
+			input: `[
+				{"id": 1, "title": "ok"},
+				{"id": 2, "title": ""},
+				{"id": 3, "title": "x", "priority": "critical"},
+				{"id": 4, "title": "x", "done": "yes"},
+				{"id": 5, "title": "x", "parent_id": 9},
+				{"id": 1, "title": "dup", "recurrence": "hourly"}
+			]`,
+			want: []rowErr{{2, "title"}, {3, ""}, {4, "done"}, {5, "parent_id"}, {6, "id"}, {6, "recurrence"}},
+		},
+		{
+			name:   "json cycle",
+			format: FormatJSON,
+			input:  `[{"id": 1, "title": "a", "parent_id": 2}, {"id": 2, "title": "b", "parent_id": 1}]`,
+			want:   []rowErr{{1, "parent_id"}, {2, "parent_id"}},
+		},
+		{
+			name:   "csv bad rows",
+			format: FormatCSV,
+			input: "title,done,priority,due_at,id,parent_id\n" +
+				"ok,false,high,2025-07-10T17:00:00Z,1,\n" +
+				",false,,,2,\n" +
+				"x,maybe,critical,tomorrow,3,\n" +
+				"x,false,,,abc,1\n" +
+				"short,false\n" +
+				"\"unterminated,false,,,,\n",
+			want: []rowErr{{3, "title"}, {4, "done"}, {4, "priority"}, {4, "due_at"}, {5, "id"}, {6, ""}, {7, ""}},
+		},
+		{
+			name:   "ics bad todos",
+			format: FormatICal,
+			input: "BEGIN:VCALENDAR\r\n" +
+				"BEGIN:VTODO\r\nUID:a\r\nSUMMARY:ok\r\nEND:VTODO\r\n" +
+				"BEGIN:VTODO\r\nUID:b\r\nPRIORITY:12\r\nDUE:soon\r\nEND:VTODO\r\n" +
+				"BEGIN:VTODO\r\nUID:a\r\nSUMMARY:dup\r\nRRULE:FREQ=MONTHLY\r\nRELATED-TO:zzz\r\nEND:VTODO\r\n" +
+				"BEGIN:VTODO\r\nSUMMARY:broken\r\nNOCOLON\r\n",
+			want: []rowErr{{6, "PRIORITY"}, {6, "DUE"}, {6, "SUMMARY"}, {11, "RRULE"}, {11, "UID"}, {11, "RELATED-TO"}, {17, ""}, {17, ""}},
+		},
+		{
+			name:   "json invalid priority is wrapped",
+			format: FormatJSON,
+			input:  `[{"title": "x", "priority": "critical"}]`,
+			want:   []rowErr{{1, ""}},
+			is:     ErrInvalidPriority,
+		},
+		{
+			name:   "ics invalid recurrence is wrapped",
+			format: FormatICal,
+			input:  "BEGIN:VCALENDAR\nBEGIN:VTODO\nSUMMARY:x\nX-TASKMANAGER-RECURRENCE:0 0 30 2 *\nEND:VTODO\nEND:VCALENDAR\n",
+			want:   []rowErr{{2, "RRULE"}},
+			is:     ErrInvalidRecurrence,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tm := NewTaskManager()
+			_, err := tm.Import(strings.NewReader(tt.input), tt.format)
+
+			var importErr *ImportError
+			if !errors.As(err, &importErr) {
+				t.Fatalf("Expected *ImportError, got %v", err)
+			}
+			got := []rowErr{}
+			for _, row := range importErr.Rows {
+				got = append(got, rowErr{row.Row, row.Field})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected row errors %v, got %v\n%v", tt.want, got, err)
+			}
+			if tt.is != nil && !errors.Is(err, tt.is) {
+				t.Errorf("Expected error to wrap %v, got %v", tt.is, err)
+			}
+			if page, _ := tm.ListTasks(TaskQuery{}); page.Total != 0 {
+				t.Errorf("Expected nothing to be imported, got %d tasks", page.Total)
+			}
+		})
+	}
+}
+
+func TestImportInvalidFile(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		input  string
+	}{
+		{"json not an array", FormatJSON, `{"title": "x"}`},
+		{"csv no header", FormatCSV, ""},
+		{"csv no title column", FormatCSV, "name,done\nx,false\n"},
+		{"ics no calendar", FormatICal, "SUMMARY:x\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewTaskManager().Import(strings.NewReader(tt.input), tt.format)
+			var importErr *ImportError
+			if err == nil || errors.As(err, &importErr) {
+				t.Errorf("Expected a file level error, got %v", err)
+			}
+		})
+	}
+
+	if _, err := NewTaskManager().Import(strings.NewReader(""), "xml"); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("Expected ErrUnknownFormat, got %v", err)
+	}
+	if err := NewTaskManager().Export(&bytes.Buffer{}, "xml"); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("Expected ErrUnknownFormat, got %v", err)
+	}
+}
+
+// failingUpdateStore rejects changes to existing tasks once fail is set
+type failingUpdateStore struct {
+	TaskStore
+	fail bool
+}
+
+func (s *failingUpdateStore) Update(id int, fn func(task *Task) error) error {
+	if s.fail {
+		return errors.New("disk full")
+	}
+	return s.TaskStore.Update(id, fn)
+}
+
+func TestImportUndoneAfterLateFailure(t *testing.T) {
+	store := &failingUpdateStore{TaskStore: NewMemoryStore()}
+	tm := NewTaskManagerWithStore(store)
+	kept, _ := tm.AddTask("Existing", "")
+
+	// The roll-up of the recurring parent fails after every row was created
+	data := `[
+		{"id": 1, "title": "Weekly review", "recurrence": "weekly"},
+		{"id": 2, "parent_id": 1, "title": "Collect notes", "done": true}
+	]`
+	store.fail = true
+	if _, err := tm.Import(strings.NewReader(data), FormatJSON); err == nil {
+		t.Fatal("Expected the failed roll-up to fail the import")
+	}
+
+	page, _ := tm.ListTasks(TaskQuery{})
+	if page.Total != 1 || page.Tasks[0].ID != kept.ID {
+		t.Errorf("Expected only the existing task to remain, got %+v", page.Tasks)
+	}
+}